@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -24,14 +25,25 @@ const (
 // AirVantage API client using oAuth2
 type AirVantage struct {
 	client     *http.Client
+	tokens     *tokenSource
+	log        *slog.Logger
+	retry      RetryPolicy
+	limiter    *RateLimiter
+	inFlight   chan struct{}
 	CompanyUID string
 	Debug      bool
 	baseURLv1  *url.URL
 	baseURLv2  *url.URL
 }
 
-// NewClient logins to AirVantage an returns a new API client.
-func NewClient(host, clientID, clientSecret string) (*AirVantage, error) {
+// NewClient returns a new API client. The login to AirVantage happens on the
+// first API call.
+func NewClient(host, clientID, clientSecret string, opts ...Option) (*AirVantage, error) {
+
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
 
 	scheme := "https"
 	if strings.HasPrefix(host, "http://") {
@@ -43,45 +55,226 @@ func NewClient(host, clientID, clientSecret string) (*AirVantage, error) {
 
 	oauthURL := &url.URL{Host: host, Scheme: scheme, Path: "/api/oauth/"}
 
-    conf := &clientcredentials.Config{
-        ClientID:     clientID,
-        ClientSecret: clientSecret,
-        TokenURL: oauthURL.ResolveReference(&url.URL{Path: "token"}).String(),
-    }
+	conf := &clientcredentials.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TokenURL:     oauthURL.ResolveReference(&url.URL{Path: "token"}).String(),
+	}
+
+	base, err := o.baseTransport()
+	if err != nil {
+		return nil, err
+	}
+
+	tokenTimeout := o.timeout
+	if tokenTimeout == 0 && (o.httpClient == nil || o.httpClient.Timeout == 0) {
+		tokenTimeout = defaultTimeout
+	}
+	tokens := newTokenSource(conf, o.newHTTPClient(base, tokenTimeout))
+
+	retry := DefaultRetryPolicy
+	if o.retry != nil {
+		retry = *o.retry
+	}
 
-	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, http.Client{Timeout: defaultTimeout})
+	var inFlight chan struct{}
+	if o.maxInFlight > 0 {
+		inFlight = make(chan struct{}, o.maxInFlight)
+	}
 
 	return &AirVantage{
-			client:    conf.Client(ctx),
-			baseURLv1: &url.URL{Host: host, Scheme: scheme, Path: "/api/v1/"},
-			baseURLv2: &url.URL{Host: host, Scheme: scheme, Path: "/api/v2/"},
+			client:     o.newHTTPClient(&authTransport{tokens: tokens, base: base}, o.timeout),
+			tokens:     tokens,
+			log:        o.logger,
+			retry:      retry,
+			limiter:    o.limiter,
+			inFlight:   inFlight,
+			CompanyUID: o.company,
+			baseURLv1:  &url.URL{Host: host, Scheme: scheme, Path: "/api/v1/"},
+			baseURLv2:  &url.URL{Host: host, Scheme: scheme, Path: "/api/v2/"},
 		},
 		nil
 }
 
+// NewClientContext logins to AirVantage an returns a new API client.
+// ctx is only used for the login.
+func NewClientContext(ctx context.Context, host, clientID, clientSecret string, opts ...Option) (*AirVantage, error) {
+	av, err := NewClient(host, clientID, clientSecret, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := av.tokens.token(ctx); err != nil {
+		return nil, err
+	}
+
+	return av, nil
+}
+
+// logger returns the logger of the client, or the default one.
+func (av *AirVantage) logger() *slog.Logger {
+	if av.log != nil {
+		return av.log
+	}
+	return slog.Default()
+}
+
+// tokenSource caches the oAuth2 token. Unlike oauth2.ReuseTokenSource, the
+// token is fetched with the context of the request needing it.
+type tokenSource struct {
+	conf *clientcredentials.Config
+	hc   *http.Client
+	lock chan struct{}
+	tok  *oauth2.Token
+}
+
+func newTokenSource(conf *clientcredentials.Config, hc *http.Client) *tokenSource {
+	return &tokenSource{conf: conf, hc: hc, lock: make(chan struct{}, 1)}
+}
+
+func (ts *tokenSource) token(ctx context.Context) (*oauth2.Token, error) {
+	select {
+	case ts.lock <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-ts.lock }()
+
+	if ts.tok.Valid() {
+		return ts.tok, nil
+	}
+
+	tok, err := ts.conf.Token(context.WithValue(ctx, oauth2.HTTPClient, ts.hc))
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.Response != nil {
+			return nil, &APIError{
+				StatusCode: retrieveErr.Response.StatusCode,
+				Method:     http.MethodPost,
+				URL:        ts.conf.TokenURL,
+				Code:       retrieveErr.ErrorCode,
+				err:        err,
+			}
+		}
+		return nil, err
+	}
+	ts.tok = tok
+
+	return tok, nil
+}
+
+// authTransport adds the oAuth2 token to the requests.
+type authTransport struct {
+	tokens *tokenSource
+	base   http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tok, err := t.tokens.token(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	authReq := req.Clone(req.Context())
+	tok.SetAuthHeader(authReq)
+
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(authReq)
+}
+
+// do sends the request with the API client.
+func (av *AirVantage) do(req *http.Request) (*http.Response, error) {
+	return av.doWithRetry(req)
+}
+
 // get with smart URL formatting (API v1)
-func (av *AirVantage) get(format string, a ...any) (*http.Response, error) {
-	return av.client.Get(av.URL(format, a...))
+func (av *AirVantage) get(ctx context.Context, format string, a ...any) (*http.Response, error) {
+	return av.getURL(ctx, av.URL(format, a...))
 }
 
 // get with smart URL formatting (API v2)
-func (av *AirVantage) getV2(format string, a ...any) (*http.Response, error) {
-	return av.client.Get(av.URLv2(format, a...))
+func (av *AirVantage) getV2(ctx context.Context, format string, a ...any) (*http.Response, error) {
+	return av.getURL(ctx, av.URLv2(format, a...))
 }
 
 // get with query parameters (API v1)
-func (av *AirVantage) getWithParams(path string, params url.Values) (*http.Response, error) {
-	copy := url.Values{}
-	for k := range params {
-		copy.Add(k, params.Get(k))
-	}
+func (av *AirVantage) getWithParams(ctx context.Context, path string, params url.Values) (*http.Response, error) {
+	copy := cloneValues(params)
 	if av.CompanyUID != "" && !copy.Has("company") {
 		copy.Add("company", av.CompanyUID)
 	}
-	return av.client.Get(av.baseURLv1.ResolveReference(&url.URL{Path: path, RawQuery: copy.Encode()}).String())
+	return av.getURL(ctx, av.baseURLv1.ResolveReference(&url.URL{Path: path, RawQuery: copy.Encode()}).String())
+}
+
+// cloneValues returns a copy of query parameters, never nil.
+func cloneValues(params url.Values) url.Values {
+	copy := make(url.Values, len(params))
+	for k, v := range params {
+		copy[k] = append([]string(nil), v...)
+	}
+	return copy
+}
+
+func (av *AirVantage) getURL(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return av.do(req)
+}
+
+// post a body of the given content type
+func (av *AirVantage) post(ctx context.Context, url, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return av.do(req)
 }
 
-type apiError struct {
+// sendJSON sends v as a JSON body with the given method
+func (av *AirVantage) sendJSON(ctx context.Context, method, url string, v any) (*http.Response, error) {
+	js, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	av.logger().Debug("HTTP "+method, "url", url, "json", string(js))
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(js))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return av.do(req)
+}
+
+// delete the resource at the given URL
+func (av *AirVantage) delete(ctx context.Context, url string) error {
+	av.logger().Debug("HTTP DELETE", "url", url)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := av.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return av.parseError(resp)
+}
+
+// jsonError is the body of an error response.
+type jsonError struct {
 	Error           string
 	ErrorParameters []string
 }
@@ -91,7 +284,7 @@ type apiError struct {
 func (av *AirVantage) parseResponse(resp *http.Response, respStruct any, maskedUrlParams ...string) error {
 	defer resp.Body.Close()
 
-	if err := av.parseError(resp); err != nil {
+	if err := av.parseError(resp, maskedUrlParams...); err != nil {
 		return err
 	}
 
@@ -105,7 +298,7 @@ func (av *AirVantage) parseResponse(resp *http.Response, respStruct any, maskedU
 		if err != nil {
 			return err
 		}
-		slog.Debug("Parsing response", "path", maskUrlParams(resp.Request.URL.String(), maskedUrlParams), "content", string(body))
+		av.logger().Debug("Parsing response", "path", maskUrlParams(resp.Request.URL.String(), maskedUrlParams), "content", string(body))
 
 		payload = bytes.NewReader(body)
 	}
@@ -123,7 +316,7 @@ func (av *AirVantage) parseResponse(resp *http.Response, respStruct any, maskedU
 func (av *AirVantage) parseResponseSerializedJava(resp *http.Response, respStruct any, pattern string, maskedUrlParams ...string) error {
 	defer resp.Body.Close()
 
-	if err := av.parseError(resp); err != nil {
+	if err := av.parseError(resp, maskedUrlParams...); err != nil {
 		return err
 	}
 
@@ -135,7 +328,7 @@ func (av *AirVantage) parseResponseSerializedJava(resp *http.Response, respStruc
 	if err != nil {
 		return err
 	}
-	slog.Debug("Parsing serialized java response", "path", maskUrlParams(resp.Request.URL.String(), maskedUrlParams), "content", string(body))
+	av.logger().Debug("Parsing serialized java response", "path", maskUrlParams(resp.Request.URL.String(), maskedUrlParams), "content", string(body))
 
 	// use a regexp to remove the Java object reference from the response
 	// it's much easier to do that rather than parsing json into a []any
@@ -149,28 +342,38 @@ func (av *AirVantage) parseResponseSerializedJava(resp *http.Response, respStruc
 	return nil
 }
 
-func (av *AirVantage) parseError(resp *http.Response) error {
-	if resp.StatusCode > 299 {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return err
-		}
-		slog.Debug("Parsing error", "path", resp.Request.URL.String(), "content", string(body))
+// parseError returns an *APIError when the response has an error status.
+func (av *AirVantage) parseError(resp *http.Response, maskedUrlParams ...string) error {
+	if resp.StatusCode < 300 {
+		return nil
+	}
 
-		if len(body) == 0 {
-			return fmt.Errorf("error %d %s", resp.StatusCode, resp.Status)
-		}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
 
-		apierror := apiError{}
-		if err = json.Unmarshal(body, &apierror); err != nil {
-			return fmt.Errorf("unable to parse API error: %s", err)
-		}
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Request-Id"),
+	}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.URL = maskUrlParams(resp.Request.URL.String(), append(maskedUrlParams, secretUrlParams...))
+		apiErr.path = resp.Request.URL.Path
+	}
+	av.logger().Debug("Parsing error", "path", apiErr.URL, "status", resp.StatusCode, "content", string(body))
 
-		if apierror.Error != "" {
-			return avError(resp.Request.URL.Path, apierror.Error, apierror.ErrorParameters)
+	if len(body) > 0 {
+		jsErr := jsonError{}
+		if err = json.Unmarshal(body, &jsErr); err != nil {
+			av.logger().Debug("Unable to parse API error", "error", err)
 		}
+		apiErr.Code = jsErr.Error
+		apiErr.Parameters = jsErr.ErrorParameters
 	}
-	return nil
+
+	return apiErr
 }
 
 // SetTimeout sets the request timeout of the HTTP client.
@@ -216,6 +419,9 @@ func (av *AirVantage) URLv2(path string, a ...any) string {
 	return av.baseURLv2.ResolveReference(&url.URL{Path: path, RawQuery: v.Encode()}).String()
 }
 
+// secretUrlParams are always masked in errors and logs.
+var secretUrlParams = []string{"AUTHKEY"}
+
 // mask the value of specified query string parameters
 func maskUrlParams(url string, maskedParams []string) string {
 	for _, param := range maskedParams {
@@ -2,8 +2,11 @@ package airvantage
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 )
@@ -189,3 +192,50 @@ func TestParseResponseSerializedJava(t *testing.T) {
 		t.Fatalf("invalid LwM2M PSK ID : expected: %v, got: %v", "FFFFE31330FD55964B866F02C1A0D6E7", res[0].CommInfos[0].Lwm2mPskIdentity)
 	}
 }
+
+func TestContextCancellation(t *testing.T) {
+	tokenCalls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/oauth/token":
+			tokenCalls++
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"access_token":"token","token_type":"bearer","expires_in":3600}`)
+		case "/api/v1/systems/uid":
+			if r.Header.Get("Authorization") != "Bearer token" {
+				t.Errorf("missing token, got: %v", r.Header.Get("Authorization"))
+			}
+			io.WriteString(w, `{"uid":"uid"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewClientContext(ctx, srv.URL, "id", "secret"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected: %v, got: %v", context.Canceled, err)
+	}
+
+	av, err := NewClientContext(context.Background(), srv.URL, "id", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := av.FindSystemByUIDContext(ctx, "uid"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected: %v, got: %v", context.Canceled, err)
+	}
+
+	sys, err := av.FindSystemByUID("uid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sys.UID != "uid" {
+		t.Fatalf("expected: %v, got: %v", "uid", sys.UID)
+	}
+	if tokenCalls != 1 {
+		t.Fatalf("expected 1 token request, got: %v", tokenCalls)
+	}
+}
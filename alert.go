@@ -0,0 +1,185 @@
+package airvantage
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Alert states.
+const (
+	AlertOpen         = "OPEN"
+	AlertAcknowledged = "ACKNOWLEDGED"
+	AlertClosed       = "CLOSED"
+)
+
+// An Alert is raised by an alert rule on a system.
+type Alert struct {
+	UID     string `json:"uid"`
+	Date    AVTime `json:"date"`
+	Rule    string `json:"rule"`
+	System  string `json:"target"`
+	State   string `json:"state"`
+	Message string `json:"message,omitempty"`
+	// AcknowledgedAt and AcknowledgedBy are set once the alert is acknowledged.
+	AcknowledgedAt AVTime `json:"acknowledgedAt,omitempty"`
+	AcknowledgedBy string `json:"acknowledgedBy,omitempty"`
+}
+
+// AlertQuery filters the alerts. The empty fields do not filter.
+type AlertQuery struct {
+	// Systems are the UIDs of the systems on which the alerts were raised.
+	Systems []string
+	// Rules are the UIDs of the rules which raised the alerts.
+	Rules  []string
+	States []string
+	// From and To bound the date of the alerts, a zero bound leaving the
+	// range open on that side.
+	From, To AVTime
+}
+
+// values returns the search criteria of the query.
+func (q *AlertQuery) values() url.Values {
+	v := url.Values{}
+	if len(q.Systems) > 0 {
+		v.Set("target", strings.Join(q.Systems, ","))
+	}
+	if len(q.Rules) > 0 {
+		v.Set("rule", strings.Join(q.Rules, ","))
+	}
+	if len(q.States) > 0 {
+		v.Set("state", strings.Join(q.States, ","))
+	}
+	if r := (timeRange{q.From, q.To}); !r.isZero() {
+		v.Set("date", r.String())
+	}
+	return v
+}
+
+// AlertPages returns the pages of the alerts matching the query, the oldest first.
+func (av *AirVantage) AlertPages(ctx context.Context, q AlertQuery) *Pages[Alert] {
+	return newPages[Alert](ctx, av, "alerts", q.values(), "", "date:asc")
+}
+
+// AllAlerts iterates over all the alerts matching the query, the oldest first.
+func (av *AirVantage) AllAlerts(ctx context.Context, q AlertQuery) iter.Seq2[Alert, error] {
+	return av.AlertPages(ctx, q).All()
+}
+
+// AcknowledgeAlerts acknowledges the alerts with the given UIDs.
+func (av *AirVantage) AcknowledgeAlerts(uids ...string) error {
+	return av.AcknowledgeAlertsContext(context.Background(), uids...)
+}
+
+// AcknowledgeAlertsContext is like AcknowledgeAlerts but uses ctx for the API calls.
+func (av *AirVantage) AcknowledgeAlertsContext(ctx context.Context, uids ...string) error {
+	return av.updateAlerts(ctx, "alerts/acknowledge", uids)
+}
+
+// CloseAlerts closes the alerts with the given UIDs.
+func (av *AirVantage) CloseAlerts(uids ...string) error {
+	return av.CloseAlertsContext(context.Background(), uids...)
+}
+
+// CloseAlertsContext is like CloseAlerts but uses ctx for the API calls.
+func (av *AirVantage) CloseAlertsContext(ctx context.Context, uids ...string) error {
+	return av.updateAlerts(ctx, "alerts/close", uids)
+}
+
+func (av *AirVantage) updateAlerts(ctx context.Context, path string, uids []string) error {
+	if len(uids) == 0 {
+		return nil
+	}
+
+	resp, err := av.sendJSON(ctx, http.MethodPost, av.URL(path), struct {
+		UIDs []string `json:"uids"`
+	}{uids})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return av.parseError(resp)
+}
+
+// WatchOptions configures WatchAlerts.
+type WatchOptions struct {
+	// Query filters the watched alerts. Its date range is ignored.
+	Query AlertQuery
+	// Since is the date of the first alerts to emit, now by default.
+	Since AVTime
+	// Interval between two polls, 30 seconds by default.
+	Interval time.Duration
+	// OnError is called when a poll fails, the watch going on with the next
+	// poll. The errors are logged by default.
+	OnError func(error)
+}
+
+// WatchAlerts polls the alerts and sends the new ones on the returned
+// channel, the oldest first, until ctx is done. Each alert is sent once: the
+// polls resume after the date of the last alert received. opts may be nil.
+func (av *AirVantage) WatchAlerts(ctx context.Context, opts *WatchOptions) <-chan Alert {
+	var o WatchOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Since == 0 {
+		o.Since = NewAVTime(time.Now())
+	}
+	if o.Interval <= 0 {
+		o.Interval = 30 * time.Second
+	}
+	if o.OnError == nil {
+		o.OnError = func(err error) {
+			av.logger().Warn("Failed to poll the alerts", "error", err)
+		}
+	}
+
+	alerts := make(chan Alert)
+	go func() {
+		defer close(alerts)
+
+		// the cursor is the date of the last alert sent, with the alerts of
+		// that date already sent
+		cursor, sent := o.Since, map[string]bool{}
+		ticker := time.NewTicker(o.Interval)
+		defer ticker.Stop()
+
+		for {
+			q := o.Query
+			q.From, q.To = cursor, 0
+			for alert, err := range av.AllAlerts(ctx, q) {
+				if err != nil {
+					if ctx.Err() == nil {
+						o.OnError(err)
+					}
+					break
+				}
+				if alert.Date < cursor || (alert.Date == cursor && sent[alert.UID]) {
+					continue
+				}
+
+				select {
+				case alerts <- alert:
+				case <-ctx.Done():
+					return
+				}
+
+				if alert.Date > cursor {
+					cursor, sent = alert.Date, map[string]bool{}
+				}
+				sent[alert.UID] = true
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return alerts
+}
@@ -0,0 +1,119 @@
+package airvantage_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+	"github.com/AirVantage/airvantage-api-go/avtest"
+)
+
+func TestAlerts(t *testing.T) {
+	srv, av := newServer(t)
+	ctx := context.Background()
+
+	a1 := srv.RaiseAlert(airvantage.Alert{Rule: "r1", System: "s1", Date: 1000})
+	a2 := srv.RaiseAlert(airvantage.Alert{Rule: "r2", System: "s1", Date: 2000})
+	a3 := srv.RaiseAlert(airvantage.Alert{Rule: "r1", System: "s2", Date: 3000})
+
+	tests := []struct {
+		name     string
+		query    airvantage.AlertQuery
+		expected []string
+	}{
+		{"all", airvantage.AlertQuery{}, []string{a1.UID, a2.UID, a3.UID}},
+		{"system", airvantage.AlertQuery{Systems: []string{"s1"}}, []string{a1.UID, a2.UID}},
+		{"rule", airvantage.AlertQuery{Rules: []string{"r1"}}, []string{a1.UID, a3.UID}},
+		{"dates", airvantage.AlertQuery{From: 1500, To: 3000}, []string{a2.UID, a3.UID}},
+	}
+	for _, tt := range tests {
+		var uids []string
+		for alert, err := range av.AllAlerts(ctx, tt.query) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			uids = append(uids, alert.UID)
+		}
+		if len(uids) != len(tt.expected) || (len(uids) > 0 && uids[0] != tt.expected[0]) {
+			t.Errorf("%s: expected %v, got: %v", tt.name, tt.expected, uids)
+		}
+	}
+
+	if err := av.AcknowledgeAlertsContext(ctx, a1.UID, a2.UID); err != nil {
+		t.Fatal(err)
+	}
+	if err := av.CloseAlertsContext(ctx, a3.UID); err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for alert, err := range av.AllAlerts(ctx, airvantage.AlertQuery{States: []string{airvantage.AlertAcknowledged}}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if alert.AcknowledgedAt == 0 {
+			t.Fatalf("expected an acknowledgement date, got: %+v", alert)
+		}
+		n++
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 acknowledged alerts, got: %d", n)
+	}
+}
+
+func TestWatchAlerts(t *testing.T) {
+	srv, av := newServer(t, airvantage.WithRetryPolicy(airvantage.RetryPolicy{MaxAttempts: 1}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv.RaiseAlert(airvantage.Alert{Rule: "old", Date: 500})
+	var errs atomic.Int32
+	alerts := av.WatchAlerts(ctx, &airvantage.WatchOptions{
+		Since:    1000,
+		Interval: 10 * time.Millisecond,
+		OnError:  func(error) { errs.Add(1) },
+	})
+
+	receive := func() airvantage.Alert {
+		t.Helper()
+		select {
+		case alert := <-alerts:
+			return alert
+		case <-time.After(time.Second):
+			t.Fatal("no alert received")
+			return airvantage.Alert{}
+		}
+	}
+
+	// alerts of the same date are all sent once
+	a1 := srv.RaiseAlert(airvantage.Alert{Rule: "r1", Date: 1000})
+	a2 := srv.RaiseAlert(airvantage.Alert{Rule: "r2", Date: 1000})
+	if got := []string{receive().UID, receive().UID}; got[0] != a1.UID || got[1] != a2.UID {
+		t.Fatalf("expected %v, got: %v", []string{a1.UID, a2.UID}, got)
+	}
+
+	// the watch goes on after an error
+	srv.InjectFault(avtest.Fault{Path: "/api/v1/alerts", Status: http.StatusInternalServerError, Times: 1})
+	time.Sleep(30 * time.Millisecond)
+	a3 := srv.RaiseAlert(airvantage.Alert{Rule: "r3", Date: 2000})
+	if alert := receive(); alert.UID != a3.UID {
+		t.Fatalf("expected %v, got: %+v", a3.UID, alert)
+	}
+	if errs.Load() != 1 {
+		t.Fatalf("expected 1 error, got: %d", errs.Load())
+	}
+
+	select {
+	case alert := <-alerts:
+		t.Fatalf("unexpected alert: %+v", alert)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	if _, ok := <-alerts; ok {
+		t.Fatal("expected the channel to be closed")
+	}
+}
@@ -0,0 +1,295 @@
+package airvantage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// AlertOperator compares the operands of an alert condition.
+type AlertOperator string
+
+const (
+	AlertEquals         AlertOperator = "EQUALS"
+	AlertNotEquals      AlertOperator = "NOT_EQUALS"
+	AlertGreater        AlertOperator = "GT"
+	AlertGreaterOrEqual AlertOperator = "GTE"
+	AlertLower          AlertOperator = "LT"
+	AlertLowerOrEqual   AlertOperator = "LTE"
+	AlertContains       AlertOperator = "CONTAINS"
+)
+
+var alertOperators = []AlertOperator{AlertEquals, AlertNotEquals, AlertGreater, AlertGreaterOrEqual, AlertLower, AlertLowerOrEqual, AlertContains}
+
+// Alert attributes, compared by the alert conditions.
+const (
+	// AttributeData is the value of a data path, set in AlertAttribute.Path.
+	AttributeData = "system.data"
+	// AttributeCommStatus is the communication status of a system.
+	AttributeCommStatus = "system.commStatus"
+	// AttributeSystemPrefix prefixes the System fields, for example "system.lifeCycleState".
+	AttributeSystemPrefix = "system."
+)
+
+// TargetSystem is the target type of the alert rules on systems.
+const TargetSystem = "SYSTEM"
+
+// An AlertRule raises an alert on the targeted systems when all its conditions are met.
+type AlertRule struct {
+	UID        string           `json:"uid,omitempty"`
+	Name       string           `json:"name"`
+	Message    string           `json:"message,omitempty"`
+	Active     bool             `json:"active"`
+	TargetType string           `json:"targetType"`
+	Conditions []AlertCondition `json:"conditions"`
+	// Targets selects the systems watched by the rule.
+	Targets SystemSelection `json:"targets"`
+	// Emails are notified when an alert is raised.
+	Emails []string `json:"emails,omitempty"`
+	// Callback receives the alert events, see Webhook.
+	Callback     string `json:"callback,omitempty"`
+	CreationDate AVTime `json:"creationDate,omitempty"`
+}
+
+// An AlertCondition compares two operands, an attribute and a value.
+type AlertCondition struct {
+	Operator AlertOperator  `json:"operator"`
+	Operands []AlertOperand `json:"operands"`
+}
+
+// An AlertOperand is either an attribute of the system or a value.
+type AlertOperand struct {
+	Attribute *AlertAttribute `json:"attributeId,omitempty"`
+	ValueStr  *string         `json:"valueStr,omitempty"`
+	ValueNum  *float64        `json:"valueNum,omitempty"`
+}
+
+// AlertAttribute identifies an attribute of the systems.
+type AlertAttribute struct {
+	Name string `json:"name"`
+	// Path of the data, for the AttributeData attribute.
+	Path string `json:"path,omitempty"`
+}
+
+// DataCondition compares the value of a data path.
+func DataCondition(path string, op AlertOperator, value any) AlertCondition {
+	return newAlertCondition(AlertAttribute{Name: AttributeData, Path: path}, op, value)
+}
+
+// CommStatusCondition matches the systems with the given communication status.
+func CommStatusCondition(status CommStatus) AlertCondition {
+	return newAlertCondition(AlertAttribute{Name: AttributeCommStatus}, AlertEquals, string(status))
+}
+
+// SystemCondition compares a field of the System, for example "lifeCycleState".
+func SystemCondition(field string, op AlertOperator, value any) AlertCondition {
+	return newAlertCondition(AlertAttribute{Name: AttributeSystemPrefix + field}, op, value)
+}
+
+func newAlertCondition(attr AlertAttribute, op AlertOperator, value any) AlertCondition {
+	operand := AlertOperand{}
+	switch v := value.(type) {
+	case int:
+		operand.ValueNum = ptr(float64(v))
+	case int64:
+		operand.ValueNum = ptr(float64(v))
+	case float32:
+		operand.ValueNum = ptr(float64(v))
+	case float64:
+		operand.ValueNum = &v
+	case string:
+		operand.ValueStr = &v
+	default:
+		operand.ValueStr = ptr(fmt.Sprint(v))
+	}
+	return AlertCondition{Operator: op, Operands: []AlertOperand{{Attribute: &attr}, operand}}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// Validate checks the consistency of the rule.
+func (r *AlertRule) Validate() error {
+	var errs []error
+
+	if r.Name == "" {
+		errs = append(errs, errors.New("empty alert rule name"))
+	}
+	if len(r.Conditions) == 0 {
+		errs = append(errs, errors.New("alert rule without condition"))
+	}
+	for i, cond := range r.Conditions {
+		if !slices.Contains(alertOperators, cond.Operator) {
+			errs = append(errs, fmt.Errorf("condition %d: unknown operator '%s'", i, cond.Operator))
+		}
+		if len(cond.Operands) != 2 || !slices.ContainsFunc(cond.Operands, func(op AlertOperand) bool { return op.Attribute != nil }) {
+			errs = append(errs, fmt.Errorf("condition %d: expected an attribute and a value", i))
+		}
+	}
+	if err := r.Targets.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("targets: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// CreateAlertRule creates an alert rule, targeting systems by default, and
+// returns it with its UID.
+func (av *AirVantage) CreateAlertRule(rule *AlertRule) (*AlertRule, error) {
+	return av.CreateAlertRuleContext(context.Background(), rule)
+}
+
+// CreateAlertRuleContext is like CreateAlertRule but uses ctx for the API calls.
+func (av *AirVantage) CreateAlertRuleContext(ctx context.Context, rule *AlertRule) (*AlertRule, error) {
+	if rule.TargetType == "" {
+		copy := *rule
+		copy.TargetType = TargetSystem
+		rule = &copy
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	resp, err := av.sendJSON(ctx, http.MethodPost, av.URLv2("alertrules"), rule)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &AlertRule{}
+	if err = av.parseResponse(resp, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// GetAlertRule retrieves the alert rule with the given UID.
+func (av *AirVantage) GetAlertRule(uid string) (*AlertRule, error) {
+	return av.GetAlertRuleContext(context.Background(), uid)
+}
+
+// GetAlertRuleContext is like GetAlertRule but uses ctx for the API calls.
+func (av *AirVantage) GetAlertRuleContext(ctx context.Context, uid string) (*AlertRule, error) {
+	resp, err := av.getV2(ctx, "alertrules/"+uid)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := &AlertRule{}
+	if err = av.parseResponse(resp, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// FindAlertRules returns all the alert rules of the company.
+func (av *AirVantage) FindAlertRules() ([]AlertRule, error) {
+	return av.FindAlertRulesContext(context.Background())
+}
+
+// FindAlertRulesContext is like FindAlertRules but uses ctx for the API calls.
+func (av *AirVantage) FindAlertRulesContext(ctx context.Context) ([]AlertRule, error) {
+	resp, err := av.getV2(ctx, "alertrules")
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []AlertRule
+	if err = av.parseResponse(resp, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// FindAlertRuleByName returns the alert rule with the given name, or an
+// error wrapping ErrNotFound.
+func (av *AirVantage) FindAlertRuleByName(name string) (*AlertRule, error) {
+	return av.FindAlertRuleByNameContext(context.Background(), name)
+}
+
+// FindAlertRuleByNameContext is like FindAlertRuleByName but uses ctx for the API calls.
+func (av *AirVantage) FindAlertRuleByNameContext(ctx context.Context, name string) (*AlertRule, error) {
+	rules, err := av.FindAlertRulesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rules {
+		if rules[i].Name == name {
+			return &rules[i], nil
+		}
+	}
+	return nil, fmt.Errorf("alert rule '%s': %w", name, ErrNotFound)
+}
+
+// UpdateAlertRule replaces the alert rule with the UID of rule.
+func (av *AirVantage) UpdateAlertRule(rule *AlertRule) (*AlertRule, error) {
+	return av.UpdateAlertRuleContext(context.Background(), rule)
+}
+
+// UpdateAlertRuleContext is like UpdateAlertRule but uses ctx for the API calls.
+func (av *AirVantage) UpdateAlertRuleContext(ctx context.Context, rule *AlertRule) (*AlertRule, error) {
+	if rule.UID == "" {
+		return nil, errors.New("alert rule without UID")
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	resp, err := av.sendJSON(ctx, http.MethodPut, av.URLv2("alertrules/"+rule.UID), rule)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &AlertRule{}
+	if err = av.parseResponse(resp, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// DeleteAlertRule deletes the alert rule with the given UID.
+func (av *AirVantage) DeleteAlertRule(uid string) error {
+	return av.DeleteAlertRuleContext(context.Background(), uid)
+}
+
+// DeleteAlertRuleContext is like DeleteAlertRule but uses ctx for the API calls.
+func (av *AirVantage) DeleteAlertRuleContext(ctx context.Context, uid string) error {
+	return av.delete(ctx, av.URLv2("alertrules/"+uid))
+}
+
+// EnableAlertRule activates the alert rule with the given UID.
+func (av *AirVantage) EnableAlertRule(uid string) (*AlertRule, error) {
+	return av.EnableAlertRuleContext(context.Background(), uid)
+}
+
+// EnableAlertRuleContext is like EnableAlertRule but uses ctx for the API calls.
+func (av *AirVantage) EnableAlertRuleContext(ctx context.Context, uid string) (*AlertRule, error) {
+	return av.setAlertRuleActive(ctx, uid, true)
+}
+
+// DisableAlertRule deactivates the alert rule with the given UID, which stops
+// raising alerts.
+func (av *AirVantage) DisableAlertRule(uid string) (*AlertRule, error) {
+	return av.DisableAlertRuleContext(context.Background(), uid)
+}
+
+// DisableAlertRuleContext is like DisableAlertRule but uses ctx for the API calls.
+func (av *AirVantage) DisableAlertRuleContext(ctx context.Context, uid string) (*AlertRule, error) {
+	return av.setAlertRuleActive(ctx, uid, false)
+}
+
+func (av *AirVantage) setAlertRuleActive(ctx context.Context, uid string, active bool) (*AlertRule, error) {
+	rule, err := av.GetAlertRuleContext(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if rule.Active == active {
+		return rule, nil
+	}
+
+	rule.Active = active
+	return av.UpdateAlertRuleContext(ctx, rule)
+}
@@ -0,0 +1,69 @@
+package airvantage_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+func TestAlertRules(t *testing.T) {
+	srv, av := newServer(t)
+	ctx := context.Background()
+
+	if _, err := av.CreateAlertRuleContext(ctx, &airvantage.AlertRule{Name: "invalid"}); err == nil {
+		t.Fatal("expected an error for a rule without condition nor target")
+	}
+
+	rule, err := av.CreateAlertRuleContext(ctx, &airvantage.AlertRule{
+		Name:   "overheat",
+		Active: true,
+		Conditions: []airvantage.AlertCondition{
+			airvantage.DataCondition("sensor.temperature", airvantage.AlertGreater, 80),
+			airvantage.CommStatusCondition(airvantage.CommStatusOK),
+			airvantage.SystemCondition("lifeCycleState", airvantage.AlertEquals, airvantage.LifeCycleDeployed),
+		},
+		Targets: airvantage.SelectLabels("fleet"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rule.UID == "" || rule.TargetType != airvantage.TargetSystem || rule.CreationDate == 0 {
+		t.Fatalf("invalid created rule: %+v", rule)
+	}
+
+	found, err := av.FindAlertRuleByNameContext(ctx, "overheat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cond := found.Conditions[0]
+	if cond.Operands[0].Attribute.Path != "sensor.temperature" || *cond.Operands[1].ValueNum != 80 {
+		t.Fatalf("invalid data condition: %+v", cond)
+	}
+	if state := found.Conditions[2].Operands[1].ValueStr; state == nil || *state != "DEPLOYED" {
+		t.Fatalf("invalid system condition: %+v", found.Conditions[2])
+	}
+
+	if rule, err = av.DisableAlertRuleContext(ctx, rule.UID); err != nil || rule.Active {
+		t.Fatalf("expected a disabled rule, got: %+v, %v", rule, err)
+	}
+	if rules := srv.AlertRules(); len(rules) != 1 || rules[0].Active {
+		t.Fatalf("expected the rule to be disabled on the server, got: %+v", rules)
+	}
+
+	rule.Targets = airvantage.SelectSystems("sys1")
+	if rule, err = av.UpdateAlertRuleContext(ctx, rule); err != nil || rule.Targets.UIDs[0] != "sys1" {
+		t.Fatalf("expected an updated rule, got: %+v, %v", rule, err)
+	}
+
+	if err = av.DeleteAlertRuleContext(ctx, rule.UID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = av.GetAlertRuleContext(ctx, rule.UID); !errors.Is(err, airvantage.ErrNotFound) {
+		t.Fatalf("expected: %v, got: %v", airvantage.ErrNotFound, err)
+	}
+	if _, err = av.FindAlertRuleByNameContext(ctx, "overheat"); !errors.Is(err, airvantage.ErrNotFound) {
+		t.Fatalf("expected: %v, got: %v", airvantage.ErrNotFound, err)
+	}
+}
@@ -1,32 +1,63 @@
 package airvantage
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Application states.
+const (
+	ApplicationReleased   = "RELEASED"
+	ApplicationPublished  = "PUBLISHED"
+	ApplicationDeprecated = "DEPRECATED"
+)
+
+// Application categories.
+const (
+	ApplicationFirmware = "FIRMWARE"
+	ApplicationSoftware = "SOFTWARE"
 )
 
 // An Application descriptor.
 type Application struct {
-	UID                string         `json:"uid,omitempty"`
-	Name               string         `json:"name,omitempty"`
-	Revision           string         `json:"revision,omitempty"`
-	Type               string         `json:"type,omitempty"`
-	Category           string         `json:"category,omitempty"`
-	State              string         `json:"state,omitempty"`
-	Released           AVTime         `json:"released,omitempty"`
-	Published          AVTime         `json:"published,omitempty"`
-	Deprecated         AVTime         `json:"deprecated,omitempty"`
-	IsReference        bool           `json:"isReference,omitempty"`
-	IsPublic           bool           `json:"isPublic,omitempty"`
-	Labels             []string       `json:"labels,omitempty"`
-	ApplicationManager string         `json:"applicationManager,omitempty"`
-	Owner              map[string]any `json:"owner,omitempty"` // TODO: real impl.
+	UID                string   `json:"uid,omitempty"`
+	Name               string   `json:"name,omitempty"`
+	Revision           string   `json:"revision,omitempty"`
+	Type               string   `json:"type,omitempty"`
+	Category           string   `json:"category,omitempty"`
+	State              string   `json:"state,omitempty"`
+	Released           AVTime   `json:"released,omitempty"`
+	Published          AVTime   `json:"published,omitempty"`
+	Deprecated         AVTime   `json:"deprecated,omitempty"`
+	IsReference        bool     `json:"isReference,omitempty"`
+	IsPublic           bool     `json:"isPublic,omitempty"`
+	Labels             []string `json:"labels,omitempty"`
+	ApplicationManager string   `json:"applicationManager,omitempty"`
+	Owner              *Owner   `json:"owner,omitempty"`
+}
+
+// Owner is the company owning an application.
+type Owner struct {
+	UID  string `json:"uid,omitempty"`
+	Name string `json:"name,omitempty"`
 }
 
 // FindAppUID looks for an application using its name and revision,
 // checks if it is in the published state, and returns its UID.
 func (av *AirVantage) FindAppUID(name, rev string) (string, error) {
-	resp, err := av.get("applications", "name", name, "revision", rev, "fields", "uid,state", "size", 2)
+	return av.FindAppUIDContext(context.Background(), name, rev)
+}
+
+// FindAppUIDContext is like FindAppUID but uses ctx for the API calls.
+func (av *AirVantage) FindAppUIDContext(ctx context.Context, name, rev string) (string, error) {
+	resp, err := av.get(ctx, "applications", "name", name, "revision", rev, "fields", "uid,state", "size", 2)
 	if err != nil {
 		return "", err
 	}
@@ -50,16 +81,35 @@ func (av *AirVantage) FindAppUID(name, rev string) (string, error) {
 	// This only contains uid and state
 	app := res.Items[0]
 
-	if app.State != "PUBLISHED" {
+	if app.State != ApplicationPublished {
 		return "", fmt.Errorf("application '%s' is not PUBLISHED", name)
 	}
 
 	return app.UID, nil
 }
 
+// ApplicationPages returns the pages of the applications matching the criteria.
+// Parameters:
+// - criteria is a map of field->value to filter the results
+// - fields is a comma-separated list of fields to return (optional)
+// - orderBy is a comma-separated list of fields to order the results (optional)
+func (av *AirVantage) ApplicationPages(ctx context.Context, criteria url.Values, fields, orderBy string) *Pages[Application] {
+	return newPages[Application](ctx, av, "applications", criteria, fields, orderBy)
+}
+
+// AllApplications iterates over all the applications matching the criteria, see ApplicationPages.
+func (av *AirVantage) AllApplications(ctx context.Context, criteria url.Values, fields, orderBy string) iter.Seq2[Application, error] {
+	return av.ApplicationPages(ctx, criteria, fields, orderBy).All()
+}
+
 // FindAppByTypeRev retrieves an application by type and revision
 func (av *AirVantage) FindAppByTypeRev(apptype, apprev string) (*Application, error) {
-	resp, err := av.get("applications", "type", apptype, "revision", apprev)
+	return av.FindAppByTypeRevContext(context.Background(), apptype, apprev)
+}
+
+// FindAppByTypeRevContext is like FindAppByTypeRev but uses ctx for the API calls.
+func (av *AirVantage) FindAppByTypeRevContext(ctx context.Context, apptype, apprev string) (*Application, error) {
+	resp, err := av.get(ctx, "applications", "type", apptype, "revision", apprev)
 	if err != nil {
 		return nil, err
 	}
@@ -78,11 +128,33 @@ func (av *AirVantage) FindAppByTypeRev(apptype, apprev string) (*Application, er
 
 // ReleaseApplication releases an application
 func (av *AirVantage) ReleaseApplication(zipFile io.Reader) (string, error) {
+	return av.ReleaseApplicationContext(context.Background(), zipFile)
+}
+
+// ReleaseApplicationContext is like ReleaseApplication but uses ctx for the API calls.
+func (av *AirVantage) ReleaseApplicationContext(ctx context.Context, zipFile io.Reader) (string, error) {
+	return av.ReleaseApplicationWithOptionsContext(ctx, zipFile, nil)
+}
+
+// ReleaseApplicationWithOptions is like ReleaseApplication with options for
+// the release operation. opts may be nil.
+func (av *AirVantage) ReleaseApplicationWithOptions(zipFile io.Reader, opts *OperationOptions) (string, error) {
+	return av.ReleaseApplicationWithOptionsContext(context.Background(), zipFile, opts)
+}
+
+// ReleaseApplicationWithOptionsContext is like ReleaseApplicationWithOptions but uses ctx for the API calls.
+func (av *AirVantage) ReleaseApplicationWithOptionsContext(ctx context.Context, zipFile io.Reader, opts *OperationOptions) (string, error) {
+
+	// the options are sent as query parameters, the body being the zip file
+	var params []any
+	for key, field := range opts.fields() {
+		params = append(params, key, field)
+	}
 
 	// why do we need /api/v1 prefix here?
-	url := av.URL("/api/v1/operations/applications/release")
+	url := av.URL("/api/v1/operations/applications/release", params...)
 
-	resp, err := av.client.Post(url, "application/zip", zipFile)
+	resp, err := av.post(ctx, url, "application/zip", zipFile)
 	if err != nil {
 		return "", err
 	}
@@ -93,3 +165,184 @@ func (av *AirVantage) ReleaseApplication(zipFile io.Reader) (string, error) {
 	}
 	return string(res.Operation), nil
 }
+
+// ApplicationQuery filters the applications. The empty fields do not filter.
+type ApplicationQuery struct {
+	Name     string
+	Revision string
+	Type     string
+	Category string
+	States   []string
+	// IsPublic keeps the public applications if true, the private ones if false.
+	IsPublic *bool
+	// Labels keeps the applications having at least one of the labels.
+	Labels []string
+}
+
+// Values returns the search criteria of the query, for ApplicationPages.
+func (q *ApplicationQuery) Values() url.Values {
+	v := url.Values{}
+	for param, value := range map[string]string{"name": q.Name, "revision": q.Revision, "type": q.Type, "category": q.Category} {
+		if value != "" {
+			v.Set(param, value)
+		}
+	}
+	if len(q.States) > 0 {
+		v.Set("state", strings.Join(q.States, ","))
+	}
+	if q.IsPublic != nil {
+		v.Set("isPublic", strconv.FormatBool(*q.IsPublic))
+	}
+	if len(q.Labels) > 0 {
+		v.Set("labels", strings.Join(q.Labels, ","))
+	}
+	return v
+}
+
+// AllApplicationsByQuery iterates over all the applications matching the query.
+func (av *AirVantage) AllApplicationsByQuery(ctx context.Context, q ApplicationQuery) iter.Seq2[Application, error] {
+	return av.AllApplications(ctx, q.Values(), "", "")
+}
+
+// GetApplication retrieves the application with the given UID.
+func (av *AirVantage) GetApplication(uid string) (*Application, error) {
+	return av.GetApplicationContext(context.Background(), uid)
+}
+
+// GetApplicationContext is like GetApplication but uses ctx for the API calls.
+func (av *AirVantage) GetApplicationContext(ctx context.Context, uid string) (*Application, error) {
+	resp, err := av.get(ctx, "applications/"+uid)
+	if err != nil {
+		return nil, err
+	}
+
+	app := &Application{}
+	if err = av.parseResponse(resp, app); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// EditApplication updates the fields set in app of the application with the given UID.
+func (av *AirVantage) EditApplication(uid string, app *Application) (*Application, error) {
+	return av.EditApplicationContext(context.Background(), uid, app)
+}
+
+// EditApplicationContext is like EditApplication but uses ctx for the API calls.
+func (av *AirVantage) EditApplicationContext(ctx context.Context, uid string, app *Application) (*Application, error) {
+	resp, err := av.sendJSON(ctx, http.MethodPut, av.URL("applications/"+uid), app)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Application{}
+	if err = av.parseResponse(resp, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// DeleteApplication deletes the application with the given UID.
+func (av *AirVantage) DeleteApplication(uid string) error {
+	return av.DeleteApplicationContext(context.Background(), uid)
+}
+
+// DeleteApplicationContext is like DeleteApplication but uses ctx for the API calls.
+func (av *AirVantage) DeleteApplicationContext(ctx context.Context, uid string) error {
+	return av.delete(ctx, av.URL("applications/"+uid))
+}
+
+// AddApplicationLabels adds labels to an application.
+func (av *AirVantage) AddApplicationLabels(uid string, labels ...string) (*Application, error) {
+	return av.AddApplicationLabelsContext(context.Background(), uid, labels...)
+}
+
+// AddApplicationLabelsContext is like AddApplicationLabels but uses ctx for the API calls.
+func (av *AirVantage) AddApplicationLabelsContext(ctx context.Context, uid string, labels ...string) (*Application, error) {
+	return av.editApplicationLabels(ctx, uid, func(current []string) []string {
+		for _, label := range labels {
+			if !slices.Contains(current, label) {
+				current = append(current, label)
+			}
+		}
+		return current
+	})
+}
+
+// RemoveApplicationLabels removes labels from an application.
+func (av *AirVantage) RemoveApplicationLabels(uid string, labels ...string) (*Application, error) {
+	return av.RemoveApplicationLabelsContext(context.Background(), uid, labels...)
+}
+
+// RemoveApplicationLabelsContext is like RemoveApplicationLabels but uses ctx for the API calls.
+func (av *AirVantage) RemoveApplicationLabelsContext(ctx context.Context, uid string, labels ...string) (*Application, error) {
+	return av.editApplicationLabels(ctx, uid, func(current []string) []string {
+		return slices.DeleteFunc(current, func(label string) bool { return slices.Contains(labels, label) })
+	})
+}
+
+func (av *AirVantage) editApplicationLabels(ctx context.Context, uid string, edit func([]string) []string) (*Application, error) {
+	app, err := av.GetApplicationContext(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	// an empty list, not omitted, removes all the labels
+	body := struct {
+		Labels []string `json:"labels"`
+	}{edit(app.Labels)}
+	if body.Labels == nil {
+		body.Labels = []string{}
+	}
+
+	resp, err := av.sendJSON(ctx, http.MethodPut, av.URL("applications/"+uid), &body)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Application{}
+	if err = av.parseResponse(resp, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// PublishApplication launches an operation to publish a released
+// application, which can then be installed. opts may be nil.
+func (av *AirVantage) PublishApplication(uid string, opts *OperationOptions) (string, error) {
+	return av.PublishApplicationContext(context.Background(), uid, opts)
+}
+
+// PublishApplicationContext is like PublishApplication but uses ctx for the API calls.
+func (av *AirVantage) PublishApplicationContext(ctx context.Context, uid string, opts *OperationOptions) (string, error) {
+	return av.applicationOperation(ctx, "publish", uid, opts)
+}
+
+// UnpublishApplication launches an operation to unpublish an application. opts may be nil.
+func (av *AirVantage) UnpublishApplication(uid string, opts *OperationOptions) (string, error) {
+	return av.UnpublishApplicationContext(context.Background(), uid, opts)
+}
+
+// UnpublishApplicationContext is like UnpublishApplication but uses ctx for the API calls.
+func (av *AirVantage) UnpublishApplicationContext(ctx context.Context, uid string, opts *OperationOptions) (string, error) {
+	return av.applicationOperation(ctx, "unpublish", uid, opts)
+}
+
+// DeprecateApplication launches an operation to deprecate an application,
+// which can no longer be installed. opts may be nil.
+func (av *AirVantage) DeprecateApplication(uid string, opts *OperationOptions) (string, error) {
+	return av.DeprecateApplicationContext(context.Background(), uid, opts)
+}
+
+// DeprecateApplicationContext is like DeprecateApplication but uses ctx for the API calls.
+func (av *AirVantage) DeprecateApplicationContext(ctx context.Context, uid string, opts *OperationOptions) (string, error) {
+	return av.applicationOperation(ctx, "deprecate", uid, opts)
+}
+
+func (av *AirVantage) applicationOperation(ctx context.Context, action, uid string, opts *OperationOptions) (string, error) {
+	body := struct {
+		Application string `json:"application"`
+	}{uid}
+
+	return av.postOperation(ctx, "operations/applications/"+action, &body, opts)
+}
@@ -0,0 +1,110 @@
+package airvantage_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+func TestApplicationQuery(t *testing.T) {
+	srv, av := newServer(t)
+	ctx := context.Background()
+
+	a1 := srv.AddApplication(airvantage.Application{Name: "app", Revision: "1", State: airvantage.ApplicationPublished, IsPublic: true, Labels: []string{"prod"}})
+	a2 := srv.AddApplication(airvantage.Application{Name: "app", Revision: "2", State: airvantage.ApplicationReleased, Labels: []string{"beta"}})
+	a3 := srv.AddApplication(airvantage.Application{Name: "other", Revision: "1", State: airvantage.ApplicationDeprecated})
+
+	public := true
+	tests := []struct {
+		name     string
+		query    airvantage.ApplicationQuery
+		expected []string
+	}{
+		{"all", airvantage.ApplicationQuery{}, []string{a1.UID, a2.UID, a3.UID}},
+		{"name", airvantage.ApplicationQuery{Name: "app"}, []string{a1.UID, a2.UID}},
+		{"states", airvantage.ApplicationQuery{States: []string{airvantage.ApplicationPublished, airvantage.ApplicationDeprecated}}, []string{a1.UID, a3.UID}},
+		{"public", airvantage.ApplicationQuery{IsPublic: &public}, []string{a1.UID}},
+		{"labels", airvantage.ApplicationQuery{Labels: []string{"beta", "test"}}, []string{a2.UID}},
+	}
+	for _, tt := range tests {
+		var uids []string
+		for app, err := range av.AllApplicationsByQuery(ctx, tt.query) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			uids = append(uids, app.UID)
+		}
+		if !slices.Equal(uids, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, uids)
+		}
+	}
+}
+
+func TestApplicationLifecycle(t *testing.T) {
+	srv, av := newServer(t)
+	ctx := context.Background()
+
+	app := srv.AddApplication(airvantage.Application{Name: "app", Revision: "1", State: airvantage.ApplicationReleased, Labels: []string{"beta"}})
+
+	if _, err := av.DeprecateApplicationContext(ctx, app.UID, nil); err == nil {
+		t.Error("deprecating a released application should fail")
+	}
+
+	steps := []struct {
+		launch   func(context.Context, string, *airvantage.OperationOptions) (string, error)
+		expected string
+	}{
+		{av.PublishApplicationContext, airvantage.ApplicationPublished},
+		{av.UnpublishApplicationContext, airvantage.ApplicationReleased},
+		{av.PublishApplicationContext, airvantage.ApplicationPublished},
+		{av.DeprecateApplicationContext, airvantage.ApplicationDeprecated},
+	}
+	for _, step := range steps {
+		opUID, err := step.launch(ctx, app.UID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if opUID == "" {
+			t.Error("no operation UID")
+		}
+
+		got, err := av.GetApplicationContext(ctx, app.UID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.State != step.expected {
+			t.Errorf("expected state %s, got %s", step.expected, got.State)
+		}
+	}
+
+	got, err := av.AddApplicationLabelsContext(ctx, app.UID, "prod", "beta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got.Labels, []string{"beta", "prod"}) {
+		t.Errorf("invalid labels after add: %v", got.Labels)
+	}
+	if got, err = av.RemoveApplicationLabelsContext(ctx, app.UID, "beta", "prod"); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Labels) != 0 {
+		t.Errorf("invalid labels after remove: %v", got.Labels)
+	}
+
+	if got, err = av.EditApplicationContext(ctx, app.UID, &airvantage.Application{IsPublic: true}); err != nil {
+		t.Fatal(err)
+	}
+	if !got.IsPublic || got.Name != "app" {
+		t.Errorf("invalid edited application: %+v", got)
+	}
+
+	if err = av.DeleteApplicationContext(ctx, app.UID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = av.GetApplicationContext(ctx, app.UID); !errors.Is(err, airvantage.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,263 @@
+// Package avapp builds and validates AirVantage application packages: zip
+// files with an app.xml manifest and the binaries of the application, as
+// released by ReleaseApplication. Validating a package locally reports the
+// manifest errors before the upload, instead of a failed release operation.
+//
+//	pkg := avapp.NewPackage(avapp.Manifest{Type: "com.example.app", Revision: "1.0.0"})
+//	pkg.AddBinary("app.tar", binary)
+//	zip, err := pkg.Zip()
+//	opUID, err := av.ReleaseApplicationContext(ctx, bytes.NewReader(zip))
+package avapp
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Namespace is the XML namespace of the manifests.
+const Namespace = "http://www.sierrawireless.com/airvantage/application/1.0"
+
+// Types of the variables, settings and command parameters.
+const (
+	TypeBoolean = "boolean"
+	TypeInt     = "int"
+	TypeDouble  = "double"
+	TypeString  = "string"
+	TypeDate    = "date"
+	TypeBinary  = "binary"
+)
+
+var dataTypes = []string{TypeBoolean, TypeInt, TypeDouble, TypeString, TypeDate, TypeBinary}
+
+// Communication protocols and data encodings.
+const (
+	ProtocolMQTT   = "MQTT"
+	ProtocolLWM2M  = "LWM2M"
+	ProtocolM3DA   = "M3DA"
+	ProtocolREST   = "REST"
+	ProtocolOMADM  = "OMADM"
+	ProtocolCustom = "CUSTOM"
+)
+
+// A Manifest is the app.xml descriptor of an application.
+type Manifest struct {
+	XMLName  xml.Name `xml:"application"`
+	Type     string   `xml:"type,attr"`
+	Name     string   `xml:"name,attr,omitempty"`
+	Revision string   `xml:"revision,attr"`
+
+	Capabilities       Capabilities        `xml:"capabilities"`
+	ApplicationManager *ApplicationManager `xml:"application-manager,omitempty"`
+	Binaries           []Binary            `xml:"binaries>binary,omitempty"`
+}
+
+// Capabilities are the communication protocols and the data of an application.
+type Capabilities struct {
+	Protocols []Protocol `xml:"communication>protocol,omitempty"`
+	Encodings []Encoding `xml:"data>encoding,omitempty"`
+}
+
+// A Protocol used by the systems to communicate with AirVantage.
+type Protocol struct {
+	// CommID identifies the systems, e.g. "SERIAL" or "IMEI".
+	CommID     string      `xml:"comm-id,attr"`
+	Type       string      `xml:"type,attr"`
+	Parameters []Parameter `xml:"parameter,omitempty"`
+}
+
+// A Parameter of a protocol.
+type Parameter struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// An Encoding groups the assets exchanged with a protocol.
+type Encoding struct {
+	Type   string  `xml:"type,attr"`
+	Assets []Asset `xml:"asset,omitempty"`
+}
+
+// An Asset is the root of a data tree.
+type Asset struct {
+	ID    string `xml:"id,attr"`
+	Label string `xml:"default-label,attr,omitempty"`
+	Data
+}
+
+// A Node of a data tree.
+type Node struct {
+	Path  string `xml:"path,attr"`
+	Label string `xml:"default-label,attr,omitempty"`
+	Data
+}
+
+// Data is the content of an asset or of a node.
+type Data struct {
+	Variables []Variable `xml:"variable,omitempty"`
+	Settings  []Variable `xml:"setting,omitempty"`
+	Commands  []Command  `xml:"command,omitempty"`
+	Nodes     []Node     `xml:"node,omitempty"`
+}
+
+// A Variable is a data read from the systems, or a setting when it can be written.
+type Variable struct {
+	Path  string `xml:"path,attr"`
+	Label string `xml:"default-label,attr,omitempty"`
+	Type  string `xml:"type,attr"`
+}
+
+// A Command sent to the systems.
+type Command struct {
+	Path       string             `xml:"path,attr"`
+	Label      string             `xml:"default-label,attr,omitempty"`
+	Parameters []CommandParameter `xml:"parameter,omitempty"`
+}
+
+// A CommandParameter is an argument of a command.
+type CommandParameter struct {
+	ID    string `xml:"id,attr"`
+	Label string `xml:"default-label,attr,omitempty"`
+	Type  string `xml:"type,attr"`
+}
+
+// ApplicationManager is the component installing the application on the systems.
+type ApplicationManager struct {
+	Use string `xml:"use,attr"`
+}
+
+// A Binary is a file of the package installed on the systems.
+type Binary struct {
+	File string `xml:"file,attr"`
+}
+
+// ParseManifest decodes an app.xml manifest.
+func ParseManifest(r io.Reader) (*Manifest, error) {
+	m := &Manifest{}
+	if err := xml.NewDecoder(r).Decode(m); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return m, nil
+}
+
+// MarshalXML encodes the manifest as an app:application element.
+func (m *Manifest) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	start := xml.StartElement{
+		Name: xml.Name{Local: "app:application"},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "xmlns:app"}, Value: Namespace}},
+	}
+	// manifest has the fields but not the method, to not recurse
+	type manifest Manifest
+	return e.EncodeElement((*manifest)(m), start)
+}
+
+// Validate checks the manifest, reporting all its errors.
+func (m *Manifest) Validate() error {
+	var errs []error
+
+	if m.Type == "" {
+		errs = append(errs, errors.New("empty application type"))
+	} else if strings.ContainsFunc(m.Type, unicode.IsSpace) {
+		errs = append(errs, fmt.Errorf("application type '%s' contains spaces", m.Type))
+	}
+	if m.Revision == "" {
+		errs = append(errs, errors.New("empty application revision"))
+	}
+
+	for i, p := range m.Capabilities.Protocols {
+		if p.Type == "" {
+			errs = append(errs, fmt.Errorf("protocol %d: empty type", i))
+		}
+		if p.CommID == "" {
+			errs = append(errs, fmt.Errorf("protocol %d: empty comm-id", i))
+		}
+	}
+
+	for _, enc := range m.Capabilities.Encodings {
+		if enc.Type == "" {
+			errs = append(errs, errors.New("encoding without type"))
+		}
+		var ids []string
+		for _, asset := range enc.Assets {
+			where := fmt.Sprintf("asset '%s'", asset.ID)
+			switch {
+			case asset.ID == "":
+				errs = append(errs, errors.New("asset without id"))
+			case slices.Contains(ids, asset.ID):
+				errs = append(errs, fmt.Errorf("%s: duplicate id", where))
+			}
+			ids = append(ids, asset.ID)
+			errs = append(errs, asset.validate(where)...)
+		}
+	}
+
+	var files []string
+	for _, bin := range m.Binaries {
+		switch {
+		case bin.File == "":
+			errs = append(errs, errors.New("binary without file"))
+		case slices.Contains(files, bin.File):
+			errs = append(errs, fmt.Errorf("binary '%s': duplicate file", bin.File))
+		}
+		files = append(files, bin.File)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks the data tree, where locating it in the errors.
+func (d *Data) validate(where string) []error {
+	var errs []error
+
+	// the paths are unique among the children of a node
+	var paths []string
+	checkPath := func(kind, path string) {
+		switch {
+		case path == "":
+			errs = append(errs, fmt.Errorf("%s: %s without path", where, kind))
+		case strings.ContainsFunc(path, unicode.IsSpace):
+			errs = append(errs, fmt.Errorf("%s: %s '%s': path contains spaces", where, kind, path))
+		case slices.Contains(paths, path):
+			errs = append(errs, fmt.Errorf("%s: %s '%s': duplicate path", where, kind, path))
+		}
+		paths = append(paths, path)
+	}
+	checkType := func(kind, name, typ string) {
+		if !slices.Contains(dataTypes, typ) {
+			errs = append(errs, fmt.Errorf("%s: %s '%s': unknown type '%s'", where, kind, name, typ))
+		}
+	}
+
+	for _, v := range d.Variables {
+		checkPath("variable", v.Path)
+		checkType("variable", v.Path, v.Type)
+	}
+	for _, v := range d.Settings {
+		checkPath("setting", v.Path)
+		checkType("setting", v.Path, v.Type)
+	}
+	for _, cmd := range d.Commands {
+		checkPath("command", cmd.Path)
+		var ids []string
+		for _, p := range cmd.Parameters {
+			switch {
+			case p.ID == "":
+				errs = append(errs, fmt.Errorf("%s: command '%s': parameter without id", where, cmd.Path))
+			case slices.Contains(ids, p.ID):
+				errs = append(errs, fmt.Errorf("%s: command '%s': duplicate parameter '%s'", where, cmd.Path, p.ID))
+			}
+			ids = append(ids, p.ID)
+			checkType("command '"+cmd.Path+"' parameter", p.ID, p.Type)
+		}
+	}
+	for _, node := range d.Nodes {
+		checkPath("node", node.Path)
+		errs = append(errs, node.validate(fmt.Sprintf("%s node '%s'", where, node.Path))...)
+	}
+
+	return errs
+}
@@ -0,0 +1,151 @@
+package avapp
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+)
+
+// ManifestFile is the path of the manifest in the packages.
+const ManifestFile = "app.xml"
+
+// A Package is an application package being built or read.
+type Package struct {
+	Manifest Manifest
+	// Files are the contents of the other files of the package, by path.
+	Files map[string][]byte
+}
+
+// NewPackage returns a package without files.
+func NewPackage(m Manifest) *Package {
+	return &Package{Manifest: m, Files: map[string][]byte{}}
+}
+
+// AddBinary adds a file to the package and declares it in the manifest.
+func (p *Package) AddBinary(name string, content []byte) {
+	if p.Files == nil {
+		p.Files = map[string][]byte{}
+	}
+	p.Files[name] = content
+	if !slices.Contains(p.Manifest.Binaries, Binary{name}) {
+		p.Manifest.Binaries = append(p.Manifest.Binaries, Binary{name})
+	}
+}
+
+// Validate checks the manifest and that the binaries it declares are in the
+// package, reporting all the errors.
+func (p *Package) Validate() error {
+	errs := []error{p.Manifest.Validate()}
+	for _, bin := range p.Manifest.Binaries {
+		if _, ok := p.Files[bin.File]; bin.File != "" && !ok {
+			errs = append(errs, fmt.Errorf("binary '%s': missing file", bin.File))
+		}
+	}
+	for name := range p.Files {
+		if err := checkFileName(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WriteZip validates the package and writes it as a zip file to w.
+func (p *Package) WriteZip(w io.Writer) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	f, err := zw.Create(ManifestFile)
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(f, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(f)
+	enc.Indent("", "  ")
+	if err = enc.Encode(&p.Manifest); err != nil {
+		return err
+	}
+
+	// sorted for reproducible packages
+	names := make([]string, 0, len(p.Files))
+	for name := range p.Files {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if f, err = zw.Create(name); err != nil {
+			return err
+		}
+		if _, err = f.Write(p.Files[name]); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// Zip validates the package and returns it as a zip file, to be released
+// with ReleaseApplication.
+func (p *Package) Zip() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := p.WriteZip(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadPackage reads a zip package. Call Validate to check it before a release.
+func ReadPackage(r io.ReaderAt, size int64) (*Package, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Package{Files: map[string][]byte{}}
+	found := false
+	for _, file := range zr.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Name, err)
+		}
+
+		if file.Name != ManifestFile {
+			p.Files[file.Name] = content
+			continue
+		}
+		m, err := ParseManifest(bytes.NewReader(content))
+		if err != nil {
+			return nil, err
+		}
+		p.Manifest, found = *m, true
+	}
+	if !found {
+		return nil, fmt.Errorf("no %s in the package", ManifestFile)
+	}
+	return p, nil
+}
+
+// checkFileName rejects the paths which would not be extracted in the package.
+func checkFileName(name string) error {
+	if name == "" || name == ManifestFile || strings.HasPrefix(name, "/") || strings.Contains(name, `\`) ||
+		path.Clean(name) != name || name == ".." || strings.HasPrefix(name, "../") {
+		return fmt.Errorf("invalid file name '%s'", name)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package avapp_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AirVantage/airvantage-api-go/avapp"
+)
+
+func testManifest() avapp.Manifest {
+	return avapp.Manifest{
+		Type:     "com.example.app",
+		Name:     "Example",
+		Revision: "1.0.0",
+		Capabilities: avapp.Capabilities{
+			Protocols: []avapp.Protocol{{CommID: "SERIAL", Type: avapp.ProtocolMQTT}},
+			Encodings: []avapp.Encoding{{
+				Type: avapp.ProtocolMQTT,
+				Assets: []avapp.Asset{{
+					ID: "machine",
+					Data: avapp.Data{
+						Variables: []avapp.Variable{{Path: "temperature", Type: avapp.TypeDouble}},
+						Settings:  []avapp.Variable{{Path: "threshold", Type: avapp.TypeInt}},
+						Commands: []avapp.Command{{Path: "reboot", Parameters: []avapp.CommandParameter{
+							{ID: "delay", Type: avapp.TypeInt},
+						}}},
+						Nodes: []avapp.Node{{Path: "gps", Data: avapp.Data{
+							Variables: []avapp.Variable{{Path: "latitude", Type: avapp.TypeDouble}},
+						}}},
+					},
+				}},
+			}},
+		},
+		ApplicationManager: &avapp.ApplicationManager{Use: "LWM2M_SW"},
+	}
+}
+
+func TestPackageRoundTrip(t *testing.T) {
+	pkg := avapp.NewPackage(testManifest())
+	pkg.AddBinary("app.tar", []byte("binary"))
+
+	zip, err := pkg.Zip()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := avapp.ReadPackage(bytes.NewReader(zip), int64(len(zip)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = read.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if read.Manifest.XMLName.Space != avapp.Namespace {
+		t.Errorf("invalid namespace '%s'", read.Manifest.XMLName.Space)
+	}
+	read.Manifest.XMLName = pkg.Manifest.XMLName
+	if !reflect.DeepEqual(read, pkg) {
+		t.Errorf("expected %+v, got %+v", pkg, read)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		edit     func(*avapp.Package)
+		expected string
+	}{
+		{"no type", func(p *avapp.Package) { p.Manifest.Type = "" }, "empty application type"},
+		{"unknown type", func(p *avapp.Package) {
+			p.Manifest.Capabilities.Encodings[0].Assets[0].Variables[0].Type = "float"
+		}, "asset 'machine': variable 'temperature': unknown type 'float'"},
+		{"duplicate path", func(p *avapp.Package) {
+			p.Manifest.Capabilities.Encodings[0].Assets[0].Settings[0].Path = "temperature"
+		}, "asset 'machine': setting 'temperature': duplicate path"},
+		{"nested node", func(p *avapp.Package) {
+			p.Manifest.Capabilities.Encodings[0].Assets[0].Nodes[0].Variables[0].Path = ""
+		}, "asset 'machine' node 'gps': variable without path"},
+		{"missing binary", func(p *avapp.Package) {
+			p.Manifest.Binaries = append(p.Manifest.Binaries, avapp.Binary{File: "lib.so"})
+		}, "binary 'lib.so': missing file"},
+		{"file name", func(p *avapp.Package) { p.Files["../app.tar"] = nil }, "invalid file name '../app.tar'"},
+	}
+	for _, tt := range tests {
+		pkg := avapp.NewPackage(testManifest())
+		pkg.AddBinary("app.tar", []byte("binary"))
+		tt.edit(pkg)
+
+		_, err := pkg.Zip()
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%s: expected error '%s', got %v", tt.name, tt.expected, err)
+		}
+	}
+}
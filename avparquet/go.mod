@@ -0,0 +1,22 @@
+module github.com/AirVantage/airvantage-api-go/avparquet
+
+go 1.24
+
+require (
+	github.com/AirVantage/airvantage-api-go v1.2610.0
+	github.com/parquet-go/parquet-go v0.25.1
+)
+
+require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.21 // indirect
+	golang.org/x/oauth2 v0.30.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+)
+
+// The Parquet writer is developed with the API client of the same commit.
+// The replace is ignored by the users of the module, who get the required
+// version: it must be a tag of the API client with RowWriter.
+replace github.com/AirVantage/airvantage-api-go => ../
@@ -0,0 +1,61 @@
+// Package avparquet writes the rows of an AirVantage data export as Apache
+// Parquet, for the analytics tools. It is a separate module, so that the API
+// client does not depend on the Parquet library:
+//
+//	go get github.com/AirVantage/airvantage-api-go/avparquet
+//
+//	f, err := os.Create("fleet.parquet")
+//	w := avparquet.NewWriter(f)
+//	_, err = av.ExportFleetDataContext(ctx, companyUID, from, to, w, nil)
+//	err = w.Close()
+package avparquet
+
+import (
+	"io"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+	"github.com/parquet-go/parquet-go"
+)
+
+// Row is the schema of the Parquet rows. Value is the text of the value,
+// and Number is also set for the numeric values.
+type Row struct {
+	System    string   `parquet:"system,dict"`
+	Data      string   `parquet:"data,dict"`
+	Timestamp int64    `parquet:"timestamp,timestamp(millisecond)"`
+	Value     string   `parquet:"value"`
+	Number    *float64 `parquet:"number,optional"`
+}
+
+// Writer is an airvantage.RowWriter writing a Parquet file.
+type Writer struct {
+	w *parquet.GenericWriter[Row]
+}
+
+var _ airvantage.RowWriter = (*Writer)(nil)
+
+// NewWriter returns a Writer writing to w. The file is complete once the
+// Writer is closed.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: parquet.NewGenericWriter[Row](w)}
+}
+
+// WriteRow implements airvantage.RowWriter.
+func (w *Writer) WriteRow(row airvantage.DataRow) error {
+	r := Row{
+		System:    row.SystemUID,
+		Data:      row.DataID,
+		Timestamp: int64(row.Timestamp),
+		Value:     row.Text(),
+	}
+	if f, ok := row.Float64(); ok {
+		r.Number = &f
+	}
+	_, err := w.w.Write([]Row{r})
+	return err
+}
+
+// Close writes the end of the Parquet file. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	return w.w.Close()
+}
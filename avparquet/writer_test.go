@@ -0,0 +1,42 @@
+package avparquet_test
+
+import (
+	"bytes"
+	"testing"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+	"github.com/AirVantage/airvantage-api-go/avparquet"
+	"github.com/parquet-go/parquet-go"
+)
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := avparquet.NewWriter(&buf)
+
+	rows := []airvantage.DataRow{
+		{SystemUID: "s1", DataID: "temperature", Datapoint: airvantage.Datapoint{Timestamp: 1000, Value: 21.5}},
+		{SystemUID: "s1", DataID: "firmware", Datapoint: airvantage.Datapoint{Timestamp: 2000, Value: "1.2.3"}},
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := parquet.Read[avparquet.Row](bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 2 {
+		t.Fatalf("expected 2 rows, got: %+v", read)
+	}
+	if r := read[0]; r.System != "s1" || r.Data != "temperature" || r.Timestamp != 1000 || r.Value != "21.5" || r.Number == nil || *r.Number != 21.5 {
+		t.Fatalf("invalid numeric row: %+v", r)
+	}
+	if r := read[1]; r.Value != "1.2.3" || r.Number != nil {
+		t.Fatalf("invalid text row: %+v", r)
+	}
+}
@@ -0,0 +1,204 @@
+package avtest
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+// AlertRules returns the alert rules of the server.
+func (s *Server) AlertRules() []airvantage.AlertRule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rules := make([]airvantage.AlertRule, len(s.alertRules))
+	for i, rule := range s.alertRules {
+		rules[i] = *rule
+	}
+	return rules
+}
+
+// alertRule returns the alert rule with the given UID. s.mu must be held.
+func (s *Server) alertRule(uid string) *airvantage.AlertRule {
+	for _, rule := range s.alertRules {
+		if rule.UID == uid {
+			return rule
+		}
+	}
+	return nil
+}
+
+// decodeAlertRule decodes and checks the alert rule of a request.
+func decodeAlertRule(w http.ResponseWriter, r *http.Request) (*airvantage.AlertRule, bool) {
+	var rule airvantage.AlertRule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid.json", err.Error())
+		return nil, false
+	}
+	if err := rule.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, "alertrule.invalid", err.Error())
+		return nil, false
+	}
+	return &rule, true
+}
+
+func (s *Server) handleFindAlertRules(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.AlertRules())
+}
+
+func (s *Server) handleCreateAlertRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := decodeAlertRule(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule.UID = s.newUID()
+	rule.CreationDate = airvantage.NewAVTime(time.Now())
+	s.alertRules = append(s.alertRules, rule)
+	writeJSON(w, rule)
+}
+
+func (s *Server) handleGetAlertRule(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule := s.alertRule(r.PathValue("uid"))
+	if rule == nil {
+		writeError(w, http.StatusNotFound, "alertrule.not.found", r.PathValue("uid"))
+		return
+	}
+	writeJSON(w, rule)
+}
+
+func (s *Server) handleUpdateAlertRule(w http.ResponseWriter, r *http.Request) {
+	update, ok := decodeAlertRule(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule := s.alertRule(r.PathValue("uid"))
+	if rule == nil {
+		writeError(w, http.StatusNotFound, "alertrule.not.found", r.PathValue("uid"))
+		return
+	}
+	update.UID, update.CreationDate = rule.UID, rule.CreationDate
+	*rule = *update
+	writeJSON(w, rule)
+}
+
+func (s *Server) handleDeleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.alertRules, func(rule *airvantage.AlertRule) bool { return rule.UID == r.PathValue("uid") })
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "alertrule.not.found", r.PathValue("uid"))
+		return
+	}
+	s.alertRules = slices.Delete(s.alertRules, i, i+1)
+}
+
+// RaiseAlert adds an alert to the server and returns it with its UID. The
+// date defaults to now and the state to open.
+func (s *Server) RaiseAlert(alert airvantage.Alert) airvantage.Alert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if alert.UID == "" {
+		alert.UID = s.newUID()
+	}
+	if alert.Date == 0 {
+		alert.Date = airvantage.NewAVTime(time.Now())
+	}
+	if alert.State == "" {
+		alert.State = airvantage.AlertOpen
+	}
+	s.alerts = append(s.alerts, &alert)
+	return alert
+}
+
+// Alerts returns the alerts of the server.
+func (s *Server) Alerts() []airvantage.Alert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	alerts := make([]airvantage.Alert, len(s.alerts))
+	for i, alert := range s.alerts {
+		alerts[i] = *alert
+	}
+	return alerts
+}
+
+func (s *Server) handleFindAlerts(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	filters := map[string]func(*airvantage.Alert) string{
+		"target": func(alert *airvantage.Alert) string { return alert.System },
+		"rule":   func(alert *airvantage.Alert) string { return alert.Rule },
+		"state":  func(alert *airvantage.Alert) string { return alert.State },
+	}
+
+	var found []airvantage.Alert
+alerts:
+	for _, alert := range s.alerts {
+		for param, field := range filters {
+			if query.Has(param) && !slices.Contains(strings.Split(query.Get(param), ","), field(alert)) {
+				continue alerts
+			}
+		}
+		if query.Has("date") && !inRange(alert.Date, query.Get("date")) {
+			continue
+		}
+		found = append(found, *alert)
+	}
+	if strings.HasPrefix(query.Get("orderBy"), "date") {
+		slices.SortStableFunc(found, func(a, b airvantage.Alert) int { return cmp.Compare(a.Date, b.Date) })
+	}
+	writePage(w, r, found)
+}
+
+func (s *Server) handleUpdateAlerts(state string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			UIDs []string `json:"uids"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid.json", err.Error())
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for _, uid := range req.UIDs {
+			i := slices.IndexFunc(s.alerts, func(alert *airvantage.Alert) bool { return alert.UID == uid })
+			if i < 0 {
+				writeError(w, http.StatusNotFound, "alert.not.found", uid)
+				return
+			}
+		}
+		now := airvantage.NewAVTime(time.Now())
+		for _, alert := range s.alerts {
+			if !slices.Contains(req.UIDs, alert.UID) {
+				continue
+			}
+			alert.State = state
+			if state == airvantage.AlertAcknowledged {
+				alert.AcknowledgedAt, alert.AcknowledgedBy = now, ClientID
+			}
+		}
+	}
+}
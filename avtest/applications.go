@@ -0,0 +1,202 @@
+package avtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+	"github.com/AirVantage/airvantage-api-go/avapp"
+)
+
+// AddApplication adds an application to the server and returns it with its UID.
+func (s *Server) AddApplication(app airvantage.Application) airvantage.Application {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if app.UID == "" {
+		app.UID = s.newUID()
+	}
+	s.applications = append(s.applications, &app)
+	return app
+}
+
+func (s *Server) handleFindApplications(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	filters := map[string]func(*airvantage.Application) string{
+		"uid":      func(app *airvantage.Application) string { return app.UID },
+		"name":     func(app *airvantage.Application) string { return app.Name },
+		"revision": func(app *airvantage.Application) string { return app.Revision },
+		"type":     func(app *airvantage.Application) string { return app.Type },
+		"category": func(app *airvantage.Application) string { return app.Category },
+		"isPublic": func(app *airvantage.Application) string { return strconv.FormatBool(app.IsPublic) },
+	}
+
+	var found []airvantage.Application
+apps:
+	for _, app := range s.applications {
+		for param, field := range filters {
+			if query.Has(param) && query.Get(param) != field(app) {
+				continue apps
+			}
+		}
+		if states := query.Get("state"); states != "" && !slices.Contains(strings.Split(states, ","), app.State) {
+			continue
+		}
+		if labels := query.Get("labels"); labels != "" && !slices.ContainsFunc(strings.Split(labels, ","), func(label string) bool {
+			return slices.Contains(app.Labels, label)
+		}) {
+			continue
+		}
+		found = append(found, *app)
+	}
+	writePage(w, r, found)
+}
+
+// Applications returns the applications of the server.
+func (s *Server) Applications() []airvantage.Application {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	apps := make([]airvantage.Application, 0, len(s.applications))
+	for _, app := range s.applications {
+		apps = append(apps, *app)
+	}
+	return apps
+}
+
+// application returns the application with the given UID, or nil. s.mu must be held.
+func (s *Server) application(uid string) *airvantage.Application {
+	i := slices.IndexFunc(s.applications, func(app *airvantage.Application) bool { return app.UID == uid })
+	if i < 0 {
+		return nil
+	}
+	return s.applications[i]
+}
+
+func (s *Server) handleGetApplication(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	app := s.application(r.PathValue("uid"))
+	if app == nil {
+		writeError(w, http.StatusNotFound, "application.not.found", r.PathValue("uid"))
+		return
+	}
+	writeJSON(w, app)
+}
+
+func (s *Server) handleEditApplication(w http.ResponseWriter, r *http.Request) {
+	var edit json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&edit); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid.json", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	app := s.application(r.PathValue("uid"))
+	if app == nil {
+		writeError(w, http.StatusNotFound, "application.not.found", r.PathValue("uid"))
+		return
+	}
+
+	// merge the fields set in the request, an empty label list clearing the labels
+	uid := app.UID
+	json.Unmarshal(edit, app)
+	app.UID = uid
+	writeJSON(w, app)
+}
+
+func (s *Server) handleDeleteApplication(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.applications, func(app *airvantage.Application) bool { return app.UID == r.PathValue("uid") })
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "application.not.found", r.PathValue("uid"))
+		return
+	}
+	s.applications = slices.Delete(s.applications, i, i+1)
+}
+
+// applicationTransitions are the states an application must be in for each
+// operation, and the state it is moved to.
+var applicationTransitions = map[string]struct{ from, to string }{
+	"applications/publish":   {airvantage.ApplicationReleased, airvantage.ApplicationPublished},
+	"applications/unpublish": {airvantage.ApplicationPublished, airvantage.ApplicationReleased},
+	"applications/deprecate": {airvantage.ApplicationPublished, airvantage.ApplicationDeprecated},
+}
+
+// changeApplicationState applies an application operation, or writes the
+// error and returns false. s.mu must be held.
+func (s *Server) changeApplicationState(w http.ResponseWriter, path string, body []byte) bool {
+	var req struct {
+		Application string `json:"application"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid.json", err.Error())
+		return false
+	}
+
+	app := s.application(req.Application)
+	if app == nil {
+		writeError(w, http.StatusNotFound, "application.not.found", req.Application)
+		return false
+	}
+	transition := applicationTransitions[path]
+	if app.State != transition.from {
+		writeError(w, http.StatusBadRequest, "application.invalid.state", app.State)
+		return false
+	}
+
+	app.State = transition.to
+	now := airvantage.NewAVTime(time.Now())
+	switch transition.to {
+	case airvantage.ApplicationPublished:
+		app.Published = now
+	case airvantage.ApplicationDeprecated:
+		app.Deprecated = now
+	}
+	return true
+}
+
+// releaseApplication adds the application of a released package. s.mu must be held.
+func (s *Server) releaseApplication(zip []byte) error {
+	pkg, err := avapp.ReadPackage(bytes.NewReader(zip), int64(len(zip)))
+	if err != nil {
+		return err
+	}
+	if err = pkg.Validate(); err != nil {
+		return err
+	}
+
+	m := pkg.Manifest
+	if slices.ContainsFunc(s.applications, func(app *airvantage.Application) bool {
+		return app.Type == m.Type && app.Revision == m.Revision
+	}) {
+		return fmt.Errorf("application %s %s already released", m.Type, m.Revision)
+	}
+	name := m.Name
+	if name == "" {
+		name = m.Type
+	}
+	s.applications = append(s.applications, &airvantage.Application{
+		UID:      s.newUID(),
+		Name:     name,
+		Type:     m.Type,
+		Revision: m.Revision,
+		State:    airvantage.ApplicationReleased,
+		Released: airvantage.NewAVTime(time.Now()),
+	})
+	return nil
+}
@@ -0,0 +1,323 @@
+package avtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// CassetteMode tells if a Cassette records or replays the HTTP exchanges.
+type CassetteMode int
+
+const (
+	// Replay serves the recorded responses, without network access.
+	Replay CassetteMode = iota
+	// Record sends the requests and records the exchanges.
+	Record
+)
+
+const redacted = "***"
+
+var (
+	// redactedParams are the secret query and form parameters.
+	redactedParams = []string{"AUTHKEY", "client_secret"}
+	// redactedHeaders are the secret headers.
+	redactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+	// redactedFields are the secret fields of the JSON bodies, along with the
+	// fields whose name contains one of redactedFieldParts, such as
+	// registrationPassword or lwm2mPskSecretHex.
+	redactedFields     = []string{"access_token", "refresh_token"}
+	redactedFieldParts = []string{"password", "secret"}
+)
+
+// Interaction is a recorded HTTP exchange.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a recorded HTTP request.
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is a recorded HTTP response.
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Cassette is an http.RoundTripper recording the HTTP exchanges in a JSON
+// fixture, or replaying them. Plug it into the client with
+// airvantage.WithTransport. The secrets (OAuth2 token and credentials,
+// AUTHKEY parameter, communication passwords, LWM2M pre-shared keys) are
+// redacted.
+//
+// In replay mode, a request is matched on its method, path and query
+// parameters, in the recorded order.
+type Cassette struct {
+	path string
+	mode CassetteMode
+	base http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewCassette returns a cassette stored in the file at path. In record mode,
+// the requests are sent with base, or http.DefaultTransport if nil, and the
+// file is written by Save. In replay mode, the file is loaded.
+func NewCassette(path string, mode CassetteMode, base http.RoundTripper) (*Cassette, error) {
+	c := &Cassette{path: path, mode: mode, base: base}
+	if mode == Record {
+		return c, nil
+	}
+
+	js, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fixture struct {
+		Interactions []Interaction `json:"interactions"`
+	}
+	if err = json.Unmarshal(js, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+	c.interactions = fixture.Interactions
+	c.used = make([]bool, len(c.interactions))
+
+	return c, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.mode == Replay {
+		return c.replay(req, body)
+	}
+	return c.record(req, body)
+}
+
+func (c *Cassette) replay(req *http.Request, body []byte) (*http.Response, error) {
+	key := matchKey(req.Method, redactURL(req.URL))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, in := range c.interactions {
+		if c.used[i] {
+			continue
+		}
+		recorded, err := url.Parse(in.Request.URL)
+		if err != nil || matchKey(in.Request.Method, recorded) != key {
+			continue
+		}
+		c.used[i] = true
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        in.Response.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(in.Response.Body)),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("avtest: no recorded interaction left for %s in cassette %s", key, c.path)
+}
+
+func (c *Cassette) record(req *http.Request, body []byte) (*http.Response, error) {
+	// a RoundTripper must not modify the request, the body is sent with a clone
+	sent := req.Clone(req.Context())
+	if body != nil {
+		sent.Body = io.NopCloser(bytes.NewReader(body))
+		sent.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	}
+
+	base := c.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(sent)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	in := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    redactURL(req.URL).String(),
+			Header: redactHeader(req.Header),
+			Body:   redactBody(req.Header.Get("Content-Type"), body),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     redactHeader(resp.Header),
+			Body:       redactBody(resp.Header.Get("Content-Type"), respBody),
+		},
+	}
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, in)
+	c.mu.Unlock()
+
+	return resp, nil
+}
+
+// Save writes the recorded interactions to the cassette file.
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	js, err := json.MarshalIndent(struct {
+		Interactions []Interaction `json:"interactions"`
+	}{c.interactions}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, append(js, '\n'), 0o644)
+}
+
+// Unused returns the recorded interactions not replayed yet.
+func (c *Cassette) Unused() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var unused []Interaction
+	for i, in := range c.interactions {
+		if !c.used[i] {
+			unused = append(unused, in)
+		}
+	}
+	return unused
+}
+
+// matchKey identifies a request by its method, path and sorted query parameters.
+func matchKey(method string, u *url.URL) string {
+	return method + " " + u.Path + "?" + u.Query().Encode()
+}
+
+func redactURL(u *url.URL) *url.URL {
+	redactedURL := *u
+	query := u.Query()
+	for _, param := range redactedParams {
+		if query.Has(param) {
+			query.Set(param, redacted)
+		}
+	}
+	redactedURL.RawQuery = query.Encode()
+	return &redactedURL
+}
+
+func redactHeader(header http.Header) http.Header {
+	header = header.Clone()
+	for _, key := range redactedHeaders {
+		if header.Get(key) != "" {
+			header.Set(key, redacted)
+		}
+	}
+	return header
+}
+
+func redactBody(contentType string, body []byte) string {
+	switch {
+	case len(body) == 0:
+		return ""
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return string(body)
+		}
+		for _, param := range redactedParams {
+			if form.Has(param) {
+				form.Set(param, redacted)
+			}
+		}
+		return form.Encode()
+	case strings.HasPrefix(contentType, "application/json"), json.Valid(body):
+		var v any
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil {
+			return string(body)
+		}
+		js, err := json.Marshal(redactJSON(v))
+		if err != nil {
+			return string(body)
+		}
+		return string(js)
+	}
+	return string(body)
+}
+
+// redactJSON replaces the values of the secret fields, at any depth.
+func redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, field := range v {
+			if isSecretField(k) && field != nil {
+				v[k] = redacted
+			} else {
+				v[k] = redactJSON(field)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = redactJSON(v[i])
+		}
+	}
+	return v
+}
+
+func isSecretField(name string) bool {
+	if containsFold(redactedFields, name) {
+		return true
+	}
+	name = strings.ToLower(name)
+	for _, part := range redactedFieldParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
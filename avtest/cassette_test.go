@@ -0,0 +1,123 @@
+package avtest_test
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+	"github.com/AirVantage/airvantage-api-go/avtest"
+)
+
+func TestCassette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	srv := avtest.NewServer()
+	sys := srv.AddSystem(airvantage.System{
+		Name:          "sys",
+		Communication: &airvantage.Communication{MQTT: airvantage.ComProto{Password: "mqtt-secret"}},
+	})
+
+	// record
+	recorder, err := avtest.NewCassette(path, avtest.Record, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	av := newClient(t, srv, airvantage.WithTransport(recorder))
+	if _, err := av.FindSystemByUID(sys.UID); err != nil {
+		t.Fatal(err)
+	}
+	av.GetSystemSecurityInfo("auth-secret", "123", "IMEI", "MQTT")
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+
+	fixture, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"mqtt-secret", "auth-secret", avtest.ClientSecret, "avtest-token"} {
+		if strings.Contains(string(fixture), secret) {
+			t.Fatalf("secret %q found in the cassette:\n%s", secret, fixture)
+		}
+	}
+
+	// replay, with the server closed
+	player, err := avtest.NewCassette(path, avtest.Replay, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	av, err = airvantage.NewClient(srv.URL, avtest.ClientID, avtest.ClientSecret, airvantage.WithTransport(player))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := av.FindSystemByUID(sys.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Name != "sys" || replayed.Communication.MQTT.Password != "***" {
+		t.Fatalf("invalid replayed system: %+v", replayed)
+	}
+	if _, err := av.GetSystemSecurityInfo("other-secret", "123", "IMEI", "MQTT"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected the recorded 404, got: %v", err)
+	}
+	if unused := player.Unused(); len(unused) != 0 {
+		t.Fatalf("expected all the interactions to be replayed, got: %+v", unused)
+	}
+
+	if _, err := av.FindSystemByUID(sys.UID); err == nil || !strings.Contains(err.Error(), "no recorded interaction") {
+		t.Fatalf("expected an unmatched request error, got: %v", err)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestCassetteRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+
+	recorder, err := avtest.NewCassette(path, avtest.Record, roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if body, _ := io.ReadAll(req.Body); string(body) != `{"name":"sys"}` {
+			t.Errorf("invalid body sent: %s", body)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`[{"commInfo":[{"lwm2mPskIdentity":"id","lwm2mPskSecretHex":"psk-secret"}]}]`)),
+			Request:    req,
+		}, nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body := io.NopCloser(strings.NewReader(`{"name":"sys"}`))
+	req, _ := http.NewRequest(http.MethodPost, "http://avtest/device/internal/securityinfo", body)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := recorder.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if req.Body != body {
+		t.Fatal("the body of the request was replaced")
+	}
+
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	fixture, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(fixture), "psk-secret") || !strings.Contains(string(fixture), "lwm2mPskIdentity") {
+		t.Fatalf("the pre-shared key is not redacted:\n%s", fixture)
+	}
+}
@@ -0,0 +1,118 @@
+package avtest
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+// history returns the datapoints of the requested system and data in the
+// requested range, the oldest first. s.mu must be held.
+func (s *Server) history(r *http.Request) map[string][]airvantage.TsValueV2 {
+	query := r.URL.Query()
+	dateRange := query.Get("from") + "," + query.Get("to")
+
+	res := map[string][]airvantage.TsValueV2{}
+	for _, id := range strings.Split(query.Get("dataIds"), ",") {
+		var values []airvantage.TsValueV2
+		for _, v := range s.data[query.Get("targetIds")][id] {
+			if inRange(v.Timestamp, dateRange) {
+				values = append(values, v)
+			}
+		}
+		slices.SortStableFunc(values, func(a, b airvantage.TsValueV2) int { return cmp.Compare(a.Timestamp, b.Timestamp) })
+		res[id] = values
+	}
+	return res
+}
+
+func (s *Server) handleRawData(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the newest points first, unless asked otherwise
+	res := s.history(r)
+	if r.URL.Query().Get("orderBy") != "timestamp:asc" {
+		for _, values := range res {
+			slices.Reverse(values)
+		}
+	}
+	if size, err := strconv.Atoi(r.URL.Query().Get("size")); err == nil {
+		for id, values := range res {
+			res[id] = values[:min(size, len(values))]
+		}
+	}
+	writeJSON(w, res)
+}
+
+func (s *Server) handleAggregatedData(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, err := strconv.ParseInt(query.Get("from"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid.from", query.Get("from"))
+		return
+	}
+	interval, err := strconv.ParseInt(query.Get("interval"), 10, 64)
+	if err != nil || interval <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid.interval", query.Get("interval"))
+		return
+	}
+	fn := airvantage.AggregateFunc(query.Get("fn"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res := map[string][]airvantage.TsValueV2{}
+	for id, values := range s.history(r) {
+		var buckets []airvantage.TsValueV2
+		var bucket []float64
+		flush := func(ts airvantage.AVTime) {
+			if len(bucket) > 0 {
+				buckets = append(buckets, airvantage.TsValueV2{Timestamp: ts, Value: aggregate(fn, bucket)})
+			}
+			bucket = nil
+		}
+
+		start := airvantage.AVTime(from)
+		for _, v := range values {
+			if end := start + airvantage.AVTime(interval); v.Timestamp >= end {
+				flush(start)
+				start += (v.Timestamp - start) / airvantage.AVTime(interval) * airvantage.AVTime(interval)
+			}
+			f, err := strconv.ParseFloat(fmt.Sprint(v.Value), 64)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "data.not.numeric", id)
+				return
+			}
+			bucket = append(bucket, f)
+		}
+		flush(start)
+		res[id] = buckets
+	}
+	writeJSON(w, res)
+}
+
+func aggregate(fn airvantage.AggregateFunc, values []float64) float64 {
+	switch fn {
+	case airvantage.AggregateMin:
+		return slices.Min(values)
+	case airvantage.AggregateMax:
+		return slices.Max(values)
+	case airvantage.AggregateCount:
+		return float64(len(values))
+	}
+
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	if fn == airvantage.AggregateMean {
+		return sum / float64(len(values))
+	}
+	return sum
+}
@@ -0,0 +1,421 @@
+package avtest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+// LaunchedOperation is an operation launched on the server, with the request
+// that launched it.
+type LaunchedOperation struct {
+	airvantage.Operation
+	// Endpoint is the path of the launch request, relative to /api/v1/operations/,
+	// for example "systems/reboot".
+	Endpoint string
+	// Body is the body of the launch request.
+	Body []byte
+	// Systems are the UIDs of the targeted systems.
+	Systems []string
+}
+
+type operation struct {
+	LaunchedOperation
+	tasks  []*airvantage.Task
+	polls  int
+	manual bool
+}
+
+// updateCounters computes the counters from the tasks.
+func (op *operation) updateCounters() {
+	op.Counters = airvantage.OperationCounters{}
+	for _, task := range op.tasks {
+		switch task.State {
+		case airvantage.TaskPending:
+			op.Counters.Pending++
+		case airvantage.TaskInProgress:
+			op.Counters.InProgress++
+		case airvantage.TaskSuccess:
+			op.Counters.Success++
+		case airvantage.TaskFailure:
+			op.Counters.Failure++
+		case airvantage.TaskCancelled:
+			op.Counters.Cancelled++
+		}
+	}
+}
+
+// FinishOperationsAfter sets the number of times an operation is fetched before
+// it finishes successfully on all its systems, which succeed gradually. The
+// default is 1, and a negative value keeps the operations in progress until
+// SetOperation is called.
+func (s *Server) FinishOperationsAfter(polls int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.finishAfter = polls
+}
+
+// FailSystems makes the tasks of the given systems fail with the cause, in
+// the operations progressing automatically.
+func (s *Server) FailSystems(cause string, uids ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, uid := range uids {
+		s.failing[uid] = cause
+	}
+}
+
+// AddOperation adds an operation to the server and returns it with its UID.
+// It does not progress automatically.
+func (s *Server) AddOperation(op airvantage.Operation) airvantage.Operation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if op.UID == "" {
+		op.UID = s.newUID()
+	}
+	s.operations = append(s.operations, &operation{LaunchedOperation: LaunchedOperation{Operation: op}, manual: true})
+	return op
+}
+
+// SetOperation sets the state and counters of an operation, which then stops
+// progressing automatically.
+func (s *Server) SetOperation(uid, state string, counters airvantage.OperationCounters) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op := s.operation(uid)
+	if op == nil {
+		return fmt.Errorf("no operation %s", uid)
+	}
+	op.State = state
+	op.Counters = counters
+	op.manual = true
+	return nil
+}
+
+// SetOperationParameters sets the launch parameters reported with an
+// operation. The server does not report them by default, as the API is not
+// known to return them.
+func (s *Server) SetOperationParameters(uid string, params json.RawMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op := s.operation(uid)
+	if op == nil {
+		return fmt.Errorf("no operation %s", uid)
+	}
+	op.Parameters = params
+	return nil
+}
+
+// Operation returns the operation with the given UID.
+func (s *Server) Operation(uid string) (LaunchedOperation, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if op := s.operation(uid); op != nil {
+		return op.LaunchedOperation, true
+	}
+	return LaunchedOperation{}, false
+}
+
+// Operations returns all the operations of the server, in launch order.
+func (s *Server) Operations() []LaunchedOperation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ops := make([]LaunchedOperation, len(s.operations))
+	for i, op := range s.operations {
+		ops[i] = op.LaunchedOperation
+	}
+	return ops
+}
+
+// operation returns the operation with the given UID. s.mu must be held.
+func (s *Server) operation(uid string) *operation {
+	for _, op := range s.operations {
+		if op.UID == uid {
+			return op
+		}
+	}
+	return nil
+}
+
+// progress moves a fetched operation forward. s.mu must be held.
+func (s *Server) progress(op *operation) {
+	if op.manual || op.State != airvantage.OperationInProgress || s.finishAfter < 0 {
+		return
+	}
+	op.polls++
+	done := len(op.tasks)
+	if op.polls < s.finishAfter {
+		done = len(op.tasks) * op.polls / s.finishAfter
+	} else {
+		op.State = airvantage.OperationFinished
+	}
+
+	now := airvantage.NewAVTime(time.Now())
+	for _, task := range op.tasks[:done] {
+		if task.State != airvantage.TaskPending {
+			continue
+		}
+		task.StartDate, task.EndDate = now, now
+		if cause, ok := s.failing[task.System]; ok {
+			task.State = airvantage.TaskFailure
+			task.FailureCause = cause
+		} else {
+			task.State = airvantage.TaskSuccess
+		}
+	}
+	op.updateCounters()
+}
+
+func (s *Server) handleGetOperation(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op := s.operation(r.PathValue("uid"))
+	if op == nil {
+		writeError(w, http.StatusNotFound, "operation.not.found", r.PathValue("uid"))
+		return
+	}
+	s.progress(op)
+	writeJSON(w, op.Operation)
+}
+
+func (s *Server) handleFindOperations(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	var found []airvantage.Operation
+	for _, op := range s.operations {
+		if uids := query.Get("uid"); uids != "" && !slices.Contains(strings.Split(uids, ","), op.UID) {
+			continue
+		}
+		if states := query.Get("state"); states != "" && !slices.Contains(strings.Split(states, ","), op.State) {
+			continue
+		}
+		found = append(found, op.Operation)
+	}
+	writePage(w, r, found)
+}
+
+func (s *Server) handleOperationPost(w http.ResponseWriter, r *http.Request) {
+	path := r.PathValue("path")
+
+	if uid, ok := strings.CutSuffix(path, "/cancel"); ok {
+		s.handleCancelOperation(w, uid)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid.body", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var systems []string
+	state := airvantage.OperationInProgress
+	switch {
+	case path == "systems/import":
+		if systems, err = s.importSystems(r, body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid.csv", err.Error())
+			return
+		}
+	case strings.HasPrefix(path, "systems/"):
+		if systems, err = s.selectSystems(body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid.json", err.Error())
+			return
+		}
+	case path == "applications/release":
+		// the package is checked asynchronously, failing the operation
+		if err = s.releaseApplication(body); err != nil {
+			state = airvantage.OperationFailed
+		}
+	case applicationTransitions[path].to != "":
+		if !s.changeApplicationState(w, path, body) {
+			return
+		}
+	default:
+		writeError(w, http.StatusNotFound, "unknown.operation", path)
+		return
+	}
+
+	op := &operation{LaunchedOperation: LaunchedOperation{
+		Operation: airvantage.Operation{
+			UID:   s.newUID(),
+			Type:  operationTypes[path],
+			State: state,
+		},
+		Endpoint: path,
+		Body:     body,
+		Systems:  systems,
+	}}
+	for _, uid := range systems {
+		op.tasks = append(op.tasks, &airvantage.Task{UID: s.newUID(), Operation: op.UID, System: uid, State: airvantage.TaskPending})
+	}
+	op.updateCounters()
+	s.operations = append(s.operations, op)
+
+	// the template operations return the operation itself
+	if path == "systems/settings" && bytes.Contains(body, []byte(`"templateName"`)) {
+		writeJSON(w, op.Operation)
+		return
+	}
+	writeJSON(w, map[string]string{"operation": op.UID})
+}
+
+// operationTypes are the types of the operations launched on the systems.
+var operationTypes = map[string]string{
+	"systems/settings":               airvantage.OperationTypeApplySettings,
+	"systems/command":                airvantage.OperationTypeSendCommand,
+	"systems/applications/install":   airvantage.OperationTypeInstallApplication,
+	"systems/applications/uninstall": airvantage.OperationTypeUninstallApplication,
+	"systems/reboot":                 airvantage.OperationTypeReboot,
+	"systems/reset":                  airvantage.OperationTypeReset,
+	"systems/file/send":              airvantage.OperationTypeSendFile,
+	"systems/data/retrieve":          airvantage.OperationTypeRetrieveData,
+	"systems/configure":              airvantage.OperationTypeConfigure,
+}
+
+// SaveSelection saves a systems query selecting the given systems, and returns
+// its UID for airvantage.SelectSavedQuery.
+func (s *Server) SaveSelection(uids ...string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uid := s.newUID()
+	s.selections[uid] = uids
+	return uid
+}
+
+// selectSystems returns the UIDs of the systems selected in a launch request. s.mu must be held.
+func (s *Server) selectSystems(body []byte) ([]string, error) {
+	var req struct {
+		Systems airvantage.SystemSelection `json:"systems"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, err
+	}
+	if err := req.Systems.Validate(); err != nil {
+		return nil, err
+	}
+
+	sel := req.Systems
+	switch {
+	case sel.Selection != "":
+		uids, ok := s.selections[sel.Selection]
+		if !ok {
+			return nil, fmt.Errorf("no selection %s", sel.Selection)
+		}
+		return uids, nil
+	case len(sel.Labels) > 0, sel.All:
+		var uids []string
+		for _, sys := range s.systems {
+			if sel.All || slices.ContainsFunc(sel.Labels, func(label string) bool { return slices.Contains(sys.Labels, label) }) {
+				uids = append(uids, sys.UID)
+			}
+		}
+		return uids, nil
+	}
+	return sel.UIDs, nil
+}
+
+// importSystems creates the systems of an import CSV. The columns "name",
+// "gateway.imei" and "gateway.serialNumber" are used. s.mu must be held.
+func (s *Server) importSystems(r *http.Request, body []byte) ([]string, error) {
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	file, _, err := r.FormFile("csv")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	var uids []string
+	for _, row := range rows[1:] {
+		sys := &airvantage.System{Gateway: &airvantage.Gateway{}}
+		for i, value := range row {
+			if i >= len(header) {
+				break
+			}
+			switch strings.TrimPrefix(header[i], "system.") {
+			case "name":
+				sys.Name = value
+			case "gateway.imei":
+				sys.Gateway.IMEI = value
+			case "gateway.serialNumber":
+				sys.Gateway.SerialNumber = value
+			}
+		}
+		uids = append(uids, s.addSystem(sys).UID)
+	}
+	return uids, nil
+}
+
+func (s *Server) handleCancelOperation(w http.ResponseWriter, uid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op := s.operation(uid)
+	if op == nil {
+		writeError(w, http.StatusNotFound, "operation.not.found", uid)
+		return
+	}
+	if op.State == airvantage.OperationInProgress {
+		op.State = airvantage.OperationCancelled
+		for _, task := range op.tasks {
+			if task.State == airvantage.TaskPending || task.State == airvantage.TaskInProgress {
+				task.State = airvantage.TaskCancelled
+			}
+		}
+		op.updateCounters()
+	}
+	writeJSON(w, op.Operation)
+}
+
+func (s *Server) handleFindTasks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	var found []airvantage.Task
+	for _, op := range s.operations {
+		if uid := query.Get("operation"); uid != "" && op.UID != uid {
+			continue
+		}
+		for _, task := range op.tasks {
+			if states := query.Get("state"); states != "" && !slices.Contains(strings.Split(states, ","), task.State) {
+				continue
+			}
+			if system := query.Get("system"); system != "" && task.System != system {
+				continue
+			}
+			found = append(found, *task)
+		}
+	}
+	writePage(w, r, found)
+}
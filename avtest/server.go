@@ -0,0 +1,222 @@
+// Package avtest provides an in-process fake AirVantage server, to test the
+// code using the API client without credentials nor network access.
+//
+//	srv := avtest.NewServer()
+//	defer srv.Close()
+//	sys := srv.AddSystem(airvantage.System{Name: "test"})
+//	av, err := srv.Client()
+package avtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+const (
+	// ClientID and ClientSecret are the credentials accepted by the server.
+	ClientID     = "avtest-client"
+	ClientSecret = "avtest-secret"
+
+	accessToken = "avtest-token"
+)
+
+// Server is a fake AirVantage server. Its state can be seeded and inspected
+// with its methods, which are safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	seq          int
+	systems      []*airvantage.System
+	applications []*airvantage.Application
+	operations   []*operation
+	data         map[string]map[string][]airvantage.TsValueV2
+	unityConf    map[string]map[string]airvantage.UnityConf
+	unityCommand map[string]map[string]airvantage.UnityCommand
+	datasets     []*airvantage.DataSet
+	alertRules   []*airvantage.AlertRule
+	alerts       []*airvantage.Alert
+	finishAfter  int
+	failing      map[string]string
+	selections   map[string][]string
+	faults       []*Fault
+}
+
+// A Fault alters the responses of the server.
+type Fault struct {
+	// Method and Path select the affected requests. An empty Method matches
+	// all the methods, and Path is a prefix of the request path.
+	Method string
+	Path   string
+	// Latency delays the response.
+	Latency time.Duration
+	// Status replaces the response by an error, for example 503 or 429.
+	Status int
+	// RetryAfter sets the Retry-After header of the error.
+	RetryAfter string
+	// Times is the number of requests affected, 0 meaning all of them.
+	Times int
+}
+
+// NewServer starts a new fake server. It must be closed after use.
+func NewServer() *Server {
+	s := &Server{
+		data:         map[string]map[string][]airvantage.TsValueV2{},
+		unityConf:    map[string]map[string]airvantage.UnityConf{},
+		unityCommand: map[string]map[string]airvantage.UnityCommand{},
+		finishAfter:  1,
+		failing:      map[string]string{},
+		selections:   map[string][]string{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/oauth/token", s.handleToken)
+
+	mux.HandleFunc("GET /api/v1/systems", s.handleFindSystems)
+	mux.HandleFunc("POST /api/v1/systems", s.handleCreateSystem)
+	mux.HandleFunc("GET /api/v1/systems/{uid}", s.handleGetSystem)
+	mux.HandleFunc("PUT /api/v1/systems/{uid}", s.handleEditSystem)
+	mux.HandleFunc("DELETE /api/v1/systems/{uid}", s.handleDeleteSystem)
+	mux.HandleFunc("GET /api/v1/systems/{uid}/data", s.handleLatestData)
+	mux.HandleFunc("GET /api/v2/systems/{uid}/data", s.handleLatestDataV2)
+	mux.HandleFunc("GET /api/v1/systems/data/fleet", s.handleFleetData)
+	mux.HandleFunc("GET /api/v1/systems/data/raw", s.handleRawData)
+	mux.HandleFunc("GET /api/v1/systems/data/aggregated", s.handleAggregatedData)
+
+	mux.HandleFunc("GET /api/v1/operations", s.handleFindOperations)
+	mux.HandleFunc("GET /api/v1/operations/tasks", s.handleFindTasks)
+	mux.HandleFunc("GET /api/v1/operations/{uid}", s.handleGetOperation)
+	mux.HandleFunc("POST /api/v1/operations/{path...}", s.handleOperationPost)
+
+	mux.HandleFunc("GET /api/v1/applications", s.handleFindApplications)
+	mux.HandleFunc("GET /api/v1/applications/{uid}", s.handleGetApplication)
+	mux.HandleFunc("PUT /api/v1/applications/{uid}", s.handleEditApplication)
+	mux.HandleFunc("DELETE /api/v1/applications/{uid}", s.handleDeleteApplication)
+
+	mux.HandleFunc("GET /api/v1/alerts", s.handleFindAlerts)
+	mux.HandleFunc("POST /api/v1/alerts/acknowledge", s.handleUpdateAlerts(airvantage.AlertAcknowledged))
+	mux.HandleFunc("POST /api/v1/alerts/close", s.handleUpdateAlerts(airvantage.AlertClosed))
+
+	mux.HandleFunc("GET /api/v1/unity/{uid}/conf", s.handleUnityConf)
+	mux.HandleFunc("GET /api/v1/unity/{uid}/command", s.handleUnityCommand)
+	mux.HandleFunc("POST /api/v1/unity/{uid}/command/dismisserror", s.handleDismissUnityCommand)
+
+	mux.HandleFunc("POST /api/v2/datasets", s.handleCreateDataset)
+
+	mux.HandleFunc("GET /api/v2/alertrules", s.handleFindAlertRules)
+	mux.HandleFunc("POST /api/v2/alertrules", s.handleCreateAlertRule)
+	mux.HandleFunc("GET /api/v2/alertrules/{uid}", s.handleGetAlertRule)
+	mux.HandleFunc("PUT /api/v2/alertrules/{uid}", s.handleUpdateAlertRule)
+	mux.HandleFunc("DELETE /api/v2/alertrules/{uid}", s.handleDeleteAlertRule)
+
+	s.Server = httptest.NewServer(s.middleware(mux))
+	return s
+}
+
+// Client returns an API client connected to the server.
+func (s *Server) Client(opts ...airvantage.Option) (*airvantage.AirVantage, error) {
+	return airvantage.NewClient(s.URL, ClientID, ClientSecret, opts...)
+}
+
+// InjectFault adds a fault to the server.
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all the faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// fault returns the fault matching the request, if any.
+func (s *Server) fault(r *http.Request) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, f := range s.faults {
+		if (f.Method != "" && f.Method != r.Method) || !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+		match := *f
+		if f.Times > 0 {
+			if f.Times--; f.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return &match
+	}
+	return nil
+}
+
+func (s *Server) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if f := s.fault(r); f != nil {
+			if f.Latency > 0 {
+				select {
+				case <-time.After(f.Latency):
+				case <-r.Context().Done():
+					return
+				}
+			}
+			if f.Status != 0 {
+				if f.RetryAfter != "" {
+					w.Header().Set("Retry-After", f.RetryAfter)
+				}
+				writeError(w, f.Status, "avtest.fault")
+				return
+			}
+		}
+
+		if r.URL.Path != "/api/oauth/token" && r.Header.Get("Authorization") != "Bearer "+accessToken {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id, secret = r.FormValue("client_id"), r.FormValue("client_secret")
+	}
+	if id != ClientID || secret != ClientSecret {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":"invalid_client"}`)
+		return
+	}
+
+	writeJSON(w, map[string]any{"access_token": accessToken, "token_type": "bearer", "expires_in": 3600})
+}
+
+// newUID returns a new unique identifier. s.mu must be held.
+func (s *Server) newUID() string {
+	s.seq++
+	return fmt.Sprintf("%032x", s.seq)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code string, parameters ...string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"error": code, "errorParameters": parameters})
+}
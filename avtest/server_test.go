@@ -0,0 +1,162 @@
+package avtest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+	"github.com/AirVantage/airvantage-api-go/avtest"
+)
+
+func newClient(t *testing.T, srv *avtest.Server, opts ...airvantage.Option) *airvantage.AirVantage {
+	t.Helper()
+
+	av, err := srv.Client(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return av
+}
+
+func TestSystems(t *testing.T) {
+	srv := avtest.NewServer()
+	defer srv.Close()
+	av := newClient(t, srv)
+
+	sys, err := av.CreateSystem(&airvantage.System{Name: "test", Gateway: &airvantage.Gateway{IMEI: "123"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = av.EditSystem(sys.UID, &airvantage.System{Labels: []string{"fleet"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := av.FindSystemsByQuery(airvantage.NewSystemQuery().IMEI("123").AllLabels("fleet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].UID != sys.UID || found[0].Name != "test" {
+		t.Fatalf("expected to find %v, got: %+v", sys.UID, found)
+	}
+
+	if err = av.DeleteSystem(sys.UID, true, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = av.FindSystemByUID(sys.UID); !errors.Is(err, airvantage.ErrNotFound) {
+		t.Fatalf("expected: %v, got: %v", airvantage.ErrNotFound, err)
+	}
+}
+
+func TestPaging(t *testing.T) {
+	srv := avtest.NewServer()
+	defer srv.Close()
+	av := newClient(t, srv)
+
+	for range 230 {
+		srv.AddSystem(airvantage.System{Name: "sys"})
+	}
+
+	pages := av.SystemPages(context.Background(), url.Values{"name": {"sys"}}, "", "")
+	n := 0
+	for _, err := range pages.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != 230 || pages.Total() != 230 {
+		t.Fatalf("expected 230 systems, got: %v (total %v)", n, pages.Total())
+	}
+}
+
+func TestOperations(t *testing.T) {
+	srv := avtest.NewServer()
+	defer srv.Close()
+	av := newClient(t, srv)
+
+	sys := srv.AddSystem(airvantage.System{Name: "sys"})
+
+	opUID, err := av.Reboot("", sys.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	launched, ok := srv.Operation(opUID)
+	if !ok || launched.Endpoint != "systems/reboot" || len(launched.Systems) != 1 {
+		t.Fatalf("invalid launched operation: %+v", launched)
+	}
+
+	op, err := av.AwaitOperation(opUID, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.State != airvantage.OperationFinished || op.Counters.Success != 1 {
+		t.Fatalf("expected a successful operation, got: %+v", op)
+	}
+
+	srv.FinishOperationsAfter(-1)
+	opUID, err = av.Reset("", sys.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op, err = av.CancelOperation(opUID); err != nil || op.State != airvantage.OperationCancelled {
+		t.Fatalf("expected a cancelled operation, got: %+v, %v", op, err)
+	}
+}
+
+func TestImportSystems(t *testing.T) {
+	srv := avtest.NewServer()
+	defer srv.Close()
+	av := newClient(t, srv)
+
+	csv := "name,gateway.imei\nsys1,1\nsys2,2\n"
+	if err := av.ImportSystems(strings.NewReader(csv), nil, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if systems := srv.Systems(); len(systems) != 2 || systems[1].Gateway.IMEI != "2" {
+		t.Fatalf("expected 2 imported systems, got: %+v", systems)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	srv := avtest.NewServer()
+	defer srv.Close()
+
+	sys := srv.AddSystem(airvantage.System{Name: "sys"})
+
+	av := newClient(t, srv, airvantage.WithRetryPolicy(airvantage.RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond}))
+	srv.InjectFault(avtest.Fault{Path: "/api/v1/systems", Status: http.StatusServiceUnavailable, Times: 1})
+	if _, err := av.FindSystemByUID(sys.UID); err != nil {
+		t.Fatalf("expected a retried request, got: %v", err)
+	}
+
+	srv.InjectFault(avtest.Fault{Path: "/api/v1/systems", Status: http.StatusTooManyRequests, RetryAfter: "0"})
+	if _, err := av.FindSystemByUID(sys.UID); !errors.Is(err, airvantage.ErrRateLimited) {
+		t.Fatalf("expected: %v, got: %v", airvantage.ErrRateLimited, err)
+	}
+	srv.ClearFaults()
+
+	srv.InjectFault(avtest.Fault{Latency: time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := av.FindSystemByUIDContext(ctx, sys.UID); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected: %v, got: %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	srv := avtest.NewServer()
+	defer srv.Close()
+
+	_, err := airvantage.NewClientContext(context.Background(), srv.URL, avtest.ClientID, "wrong")
+	if !errors.Is(err, airvantage.ErrUnauthorized) {
+		t.Fatalf("expected: %v, got: %v", airvantage.ErrUnauthorized, err)
+	}
+}
@@ -0,0 +1,392 @@
+package avtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+// AddSystem adds a system to the server and returns it with its UID.
+func (s *Server) AddSystem(sys airvantage.System) airvantage.System {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return *s.addSystem(&sys)
+}
+
+// addSystem stores a system. s.mu must be held.
+func (s *Server) addSystem(sys *airvantage.System) *airvantage.System {
+	if sys.UID == "" {
+		sys.UID = s.newUID()
+	}
+	if sys.Gateway != nil && sys.Gateway.UID == "" {
+		sys.Gateway.UID = s.newUID()
+	}
+	if sys.LifeCycleState == "" {
+		sys.LifeCycleState = string(airvantage.LifeCycleInventory)
+	}
+	s.systems = append(s.systems, sys)
+	return sys
+}
+
+// System returns the system with the given UID.
+func (s *Server) System(uid string) (airvantage.System, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sys := s.system(uid); sys != nil {
+		return *sys, true
+	}
+	return airvantage.System{}, false
+}
+
+// Systems returns all the systems of the server.
+func (s *Server) Systems() []airvantage.System {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	systems := make([]airvantage.System, len(s.systems))
+	for i, sys := range s.systems {
+		systems[i] = *sys
+	}
+	return systems
+}
+
+// system returns the system with the given UID. s.mu must be held.
+func (s *Server) system(uid string) *airvantage.System {
+	for _, sys := range s.systems {
+		if sys.UID == uid {
+			return sys
+		}
+	}
+	return nil
+}
+
+// SetData sets the datapoints of a system, returned by the data endpoints.
+func (s *Server) SetData(systemUID, dataID string, values ...airvantage.TsValueV2) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data[systemUID] == nil {
+		s.data[systemUID] = map[string][]airvantage.TsValueV2{}
+	}
+	s.data[systemUID][dataID] = values
+}
+
+// SetUnityConfig sets the configuration of a Unity gateway.
+func (s *Server) SetUnityConfig(systemUID string, conf map[string]airvantage.UnityConf) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unityConf[systemUID] = conf
+}
+
+// SetUnityCommand sets the command statuses of a Unity gateway.
+func (s *Server) SetUnityCommand(systemUID string, commands map[string]airvantage.UnityCommand) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unityCommand[systemUID] = commands
+}
+
+func (s *Server) handleFindSystems(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	var found []airvantage.System
+	for _, sys := range s.systems {
+		if matchSystem(sys, query) {
+			found = append(found, *sys)
+		}
+	}
+	writePage(w, r, found)
+}
+
+// matchSystem applies the search criteria of the API on a system.
+func matchSystem(sys *airvantage.System, query url.Values) bool {
+	if name := query.Get("name"); name != "" && sys.Name != name {
+		return false
+	}
+	if uids := query.Get("uid"); uids != "" && !slices.Contains(strings.Split(uids, ","), sys.UID) {
+		return false
+	}
+	if typ := query.Get("type"); typ != "" && sys.Type != typ {
+		return false
+	}
+	for _, group := range query["labels"] {
+		if !slices.ContainsFunc(strings.Split(group, ","), func(label string) bool {
+			return slices.Contains(sys.Labels, label)
+		}) {
+			return false
+		}
+	}
+	if states := query.Get("lifeCycleState"); states != "" && !slices.Contains(strings.Split(states, ","), sys.LifeCycleState) {
+		return false
+	}
+	if statuses := query.Get("commStatus"); statuses != "" && !slices.Contains(strings.Split(statuses, ","), sys.CommStatus) {
+		return false
+	}
+	if gateway := query.Get("gateway"); gateway != "" {
+		if sys.Gateway == nil {
+			return false
+		}
+		kind, id, _ := strings.Cut(gateway, ":")
+		switch kind {
+		case "imei":
+			if sys.Gateway.IMEI != id {
+				return false
+			}
+		case "serialNumber":
+			if sys.Gateway.SerialNumber != id {
+				return false
+			}
+		case "macAddress":
+			if sys.Gateway.MacAddress != id {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	if !inRange(sys.LastCommDate, query.Get("lastCommDate")) || !inRange(sys.CreationDate, query.Get("creationDate")) {
+		return false
+	}
+	return true
+}
+
+// inRange checks a date against a "from,to" range with optional bounds.
+func inRange(t airvantage.AVTime, dateRange string) bool {
+	if dateRange == "" {
+		return true
+	}
+	from, to, _ := strings.Cut(dateRange, ",")
+	if from, err := strconv.ParseInt(from, 10, 64); err == nil && int64(t) < from {
+		return false
+	}
+	if to, err := strconv.ParseInt(to, 10, 64); err == nil && int64(t) > to {
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleCreateSystem(w http.ResponseWriter, r *http.Request) {
+	var sys airvantage.System
+	if err := json.NewDecoder(r.Body).Decode(&sys); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid.json", err.Error())
+		return
+	}
+	if sys.Name == "" || sys.Gateway == nil {
+		writeError(w, http.StatusBadRequest, "system.missing.field", "name", "gateway")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sys.UID = ""
+	writeJSON(w, s.addSystem(&sys))
+}
+
+func (s *Server) handleGetSystem(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sys := s.system(r.PathValue("uid"))
+	if sys == nil {
+		writeError(w, http.StatusNotFound, "system.not.found", r.PathValue("uid"))
+		return
+	}
+	writeJSON(w, sys)
+}
+
+func (s *Server) handleEditSystem(w http.ResponseWriter, r *http.Request) {
+	var edit airvantage.System
+	if err := json.NewDecoder(r.Body).Decode(&edit); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid.json", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sys := s.system(r.PathValue("uid"))
+	if sys == nil {
+		writeError(w, http.StatusNotFound, "system.not.found", r.PathValue("uid"))
+		return
+	}
+
+	// merge the fields set in the request
+	js, _ := json.Marshal(&edit)
+	json.Unmarshal(js, sys)
+	writeJSON(w, sys)
+}
+
+func (s *Server) handleDeleteSystem(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.systems, func(sys *airvantage.System) bool { return sys.UID == r.PathValue("uid") })
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "system.not.found", r.PathValue("uid"))
+		return
+	}
+	s.systems = slices.Delete(s.systems, i, i+1)
+	delete(s.data, r.PathValue("uid"))
+}
+
+// latestData returns the last datapoint of the requested data of a system. s.mu must be held.
+func (s *Server) latestData(r *http.Request) map[string][]airvantage.TsValueV2 {
+	var ids []string
+	if r.URL.Query().Has("ids") {
+		ids = strings.Split(r.URL.Query().Get("ids"), ",")
+	}
+
+	res := map[string][]airvantage.TsValueV2{}
+	for id, values := range s.data[r.PathValue("uid")] {
+		if len(values) == 0 || (ids != nil && !slices.Contains(ids, id)) {
+			continue
+		}
+		latest := slices.MaxFunc(values, func(a, b airvantage.TsValueV2) int { return int(a.Timestamp - b.Timestamp) })
+		res[id] = []airvantage.TsValueV2{latest}
+	}
+	return res
+}
+
+func (s *Server) handleLatestData(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.system(r.PathValue("uid")) == nil {
+		writeError(w, http.StatusNotFound, "system.not.found", r.PathValue("uid"))
+		return
+	}
+
+	res := map[string][]airvantage.TsValue{}
+	for id, values := range s.latestData(r) {
+		res[id] = []airvantage.TsValue{{Value: values[0].Value, Timestamp: values[0].Timestamp}}
+	}
+	writeJSON(w, res)
+}
+
+func (s *Server) handleLatestDataV2(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.system(r.PathValue("uid")) == nil {
+		writeError(w, http.StatusNotFound, "system.not.found", r.PathValue("uid"))
+		return
+	}
+	writeJSON(w, s.latestData(r))
+}
+
+func (s *Server) handleFleetData(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	var ids []string
+	if query.Get("dataIds") != "" {
+		ids = strings.Split(query.Get("dataIds"), ",")
+	}
+	dateRange := query.Get("from") + "," + query.Get("to")
+
+	res := map[string]map[string][]airvantage.TsValueV2{}
+	for uid, data := range s.data {
+		for id, values := range data {
+			if ids != nil && !slices.Contains(ids, id) {
+				continue
+			}
+			for _, v := range values {
+				if !inRange(v.Timestamp, dateRange) {
+					continue
+				}
+				if res[uid] == nil {
+					res[uid] = map[string][]airvantage.TsValueV2{}
+				}
+				res[uid][id] = append(res[uid][id], v)
+			}
+		}
+	}
+	writeJSON(w, res)
+}
+
+func (s *Server) handleUnityConf(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conf, ok := s.unityConf[r.PathValue("uid")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "system.not.found", r.PathValue("uid"))
+		return
+	}
+	writeJSON(w, conf)
+}
+
+func (s *Server) handleUnityCommand(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	commands, ok := s.unityCommand[r.PathValue("uid")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "system.not.found", r.PathValue("uid"))
+		return
+	}
+	writeJSON(w, commands)
+}
+
+func (s *Server) handleDismissUnityCommand(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		CommandIDs []string `json:"commandIds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid.json", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range body.CommandIDs {
+		delete(s.unityCommand[r.PathValue("uid")], id)
+	}
+}
+
+func (s *Server) handleCreateDataset(w http.ResponseWriter, r *http.Request) {
+	var dataset airvantage.DataSet
+	if err := json.NewDecoder(r.Body).Decode(&dataset); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid.json", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dataset.Info.Uid = s.newUID()
+	s.datasets = append(s.datasets, &dataset)
+	writeJSON(w, &dataset)
+}
+
+// writePage writes a page of a search result, using the offset and size parameters.
+func writePage[T any](w http.ResponseWriter, r *http.Request, items []T) {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	size, err := strconv.Atoi(r.URL.Query().Get("size"))
+	if err != nil || size <= 0 {
+		size = 100
+	}
+
+	offset = min(max(offset, 0), len(items))
+	end := min(offset+size, len(items))
+
+	writeJSON(w, struct {
+		Items  []T `json:"items"`
+		Count  int `json:"count"`
+		Size   int `json:"size"`
+		Offset int `json:"offset"`
+	}{Items: append([]T{}, items[offset:end]...), Count: len(items), Size: end - offset, Offset: offset})
+}
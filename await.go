@@ -0,0 +1,150 @@
+package airvantage
+
+import (
+	"context"
+	"time"
+)
+
+// Operation states.
+const (
+	OperationScheduled  = "SCHEDULED"
+	OperationInProgress = "IN_PROGRESS"
+	OperationFinished   = "FINISHED"
+	OperationCancelled  = "CANCELLED"
+	OperationFailed     = "FAILED"
+)
+
+// IsTerminal tells if the operation will not progress anymore.
+func (op *Operation) IsTerminal() bool {
+	switch op.State {
+	case OperationFinished, OperationCancelled, OperationFailed:
+		return true
+	}
+	return false
+}
+
+// sub returns the counters difference with prev.
+func (oc OperationCounters) sub(prev OperationCounters) OperationCounters {
+	return OperationCounters{
+		BeingCancelled: oc.BeingCancelled - prev.BeingCancelled,
+		Cancelled:      oc.Cancelled - prev.Cancelled,
+		Failure:        oc.Failure - prev.Failure,
+		InProgress:     oc.InProgress - prev.InProgress,
+		Pending:        oc.Pending - prev.Pending,
+		Success:        oc.Success - prev.Success,
+	}
+}
+
+// AwaitOptions configures the wait of an operation.
+type AwaitOptions struct {
+	// Timeout of the wait, 0 meaning no other limit than the context.
+	Timeout time.Duration
+	// Interval is the delay between the first two polls, 1 second by default.
+	Interval time.Duration
+	// Multiplier increases the delay after each poll, 1.5 by default.
+	// Use 1 to poll at a constant interval.
+	Multiplier float64
+	// MaxInterval caps the delay between two polls, 30 seconds by default.
+	MaxInterval time.Duration
+	// OnProgress is called when the operation state or counters change, with
+	// the counters variation since the previous call.
+	OnProgress func(op Operation, delta OperationCounters)
+	// Webhook, if set, receives the callback of the operation: the operation
+	// is fetched as soon as its end is notified, without waiting for the
+	// next poll. The polls remain a fallback for lost callbacks.
+	Webhook *Webhook
+}
+
+// OperationResult is the outcome of an awaited operation.
+type OperationResult struct {
+	Operation
+	// AllSucceeded tells if the operation finished successfully on every system.
+	AllSucceeded bool
+}
+
+func (o *AwaitOptions) withDefaults() AwaitOptions {
+	var opts AwaitOptions
+	if o != nil {
+		opts = *o
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.Multiplier < 1 {
+		opts.Multiplier = 1.5
+	}
+	if opts.MaxInterval <= 0 {
+		opts.MaxInterval = 30 * time.Second
+	}
+	return opts
+}
+
+// AwaitOperationResult polls an operation until it is finished, cancelled or
+// failed. When the timeout expires, it returns the last state of the operation
+// with ErrWaitFinishedOperationTimeout. opts may be nil.
+func (av *AirVantage) AwaitOperationResult(opUID string, opts *AwaitOptions) (*OperationResult, error) {
+	return av.AwaitOperationResultContext(context.Background(), opUID, opts)
+}
+
+// AwaitOperationResultContext is like AwaitOperationResult but uses ctx for the API calls.
+func (av *AirVantage) AwaitOperationResultContext(ctx context.Context, opUID string, opts *AwaitOptions) (*OperationResult, error) {
+	o := opts.withDefaults()
+
+	var deadline <-chan time.Time
+	if o.Timeout > 0 {
+		timer := time.NewTimer(o.Timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	var notified <-chan struct{}
+	if o.Webhook != nil {
+		var stop func()
+		notified, stop = o.Webhook.waitOperation(opUID)
+		defer stop()
+	}
+
+	interval := o.Interval
+	var prev *Operation
+	for {
+		op, err := av.GetOperationContext(ctx, opUID)
+		if err != nil {
+			return nil, err
+		}
+
+		av.logger().Debug("Waiting Operation", "uid", op)
+
+		if o.OnProgress != nil && (prev == nil || prev.State != op.State || prev.Counters != op.Counters) {
+			var delta OperationCounters
+			if prev != nil {
+				delta = op.Counters.sub(prev.Counters)
+			} else {
+				delta = op.Counters
+			}
+			o.OnProgress(*op, delta)
+		}
+		prev = op
+
+		res := &OperationResult{Operation: *op}
+		if op.IsTerminal() {
+			res.AllSucceeded = op.State == OperationFinished &&
+				op.Counters.Failure == 0 && op.Counters.Cancelled == 0 && op.Counters.BeingCancelled == 0
+			return res, nil
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return res, ctx.Err()
+		case <-deadline:
+			timer.Stop()
+			return res, ErrWaitFinishedOperationTimeout
+		case <-notified:
+			timer.Stop()
+		case <-timer.C:
+		}
+
+		interval = min(time.Duration(float64(interval)*o.Multiplier), o.MaxInterval)
+	}
+}
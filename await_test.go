@@ -0,0 +1,113 @@
+package airvantage_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+	"github.com/AirVantage/airvantage-api-go/avtest"
+)
+
+// newServer starts a fake server, closed at the end of the test, and returns
+// a client connected to it.
+func newServer(t *testing.T, opts ...airvantage.Option) (*avtest.Server, *airvantage.AirVantage) {
+	t.Helper()
+
+	srv := avtest.NewServer()
+	t.Cleanup(srv.Close)
+
+	av, err := srv.Client(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, av
+}
+
+func TestAwaitOperationResult(t *testing.T) {
+	srv, av := newServer(t)
+	srv.FinishOperationsAfter(4)
+
+	csv := "name\n" + strings.Repeat("sys\n", 8)
+	var progress []airvantage.OperationCounters
+	res, err := av.ImportSystemsAwait(strings.NewReader(csv), nil, &airvantage.AwaitOptions{
+		Interval: time.Millisecond,
+		OnProgress: func(op airvantage.Operation, delta airvantage.OperationCounters) {
+			progress = append(progress, delta)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.AllSucceeded || res.Counters.Success != 8 {
+		t.Fatalf("expected a successful import, got: %+v", res)
+	}
+
+	// 2 systems succeed at each poll
+	if len(progress) != 4 {
+		t.Fatalf("expected 4 progress calls, got: %+v", progress)
+	}
+	for _, delta := range progress {
+		if delta.Success != 2 {
+			t.Fatalf("expected 2 more successes per poll, got: %+v", progress)
+		}
+	}
+
+	// terminal failures
+	op := srv.AddOperation(airvantage.Operation{State: airvantage.OperationCancelled, Counters: airvantage.OperationCounters{Success: 1, Cancelled: 1}})
+	res, err = av.AwaitOperationResult(op.UID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.AllSucceeded || res.State != airvantage.OperationCancelled {
+		t.Fatalf("expected a cancelled operation, got: %+v", res)
+	}
+
+	// timeout and cancellation
+	op = srv.AddOperation(airvantage.Operation{State: airvantage.OperationInProgress})
+	res, err = av.AwaitOperationResult(op.UID, &airvantage.AwaitOptions{Timeout: 20 * time.Millisecond, Interval: time.Millisecond})
+	if !errors.Is(err, airvantage.ErrWaitFinishedOperationTimeout) || res.State != airvantage.OperationInProgress {
+		t.Fatalf("expected: %v, got: %v", airvantage.ErrWaitFinishedOperationTimeout, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err = av.AwaitOperationResultContext(ctx, op.UID, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected: %v, got: %v", context.DeadlineExceeded, err)
+	}
+}
+
+func TestAwaitOperation(t *testing.T) {
+	srv, av := newServer(t)
+	srv.FinishOperationsAfter(-1)
+	opUID, err := av.InstallApplicationBySelection("app", airvantage.SelectSystems("s1"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a timeout of 0 polls once
+	start := time.Now()
+	if _, err = av.AwaitOperation(opUID, 0); !errors.Is(err, airvantage.ErrWaitFinishedOperationTimeout) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("the operation was polled more than once")
+	}
+
+	// only a finished operation succeeds
+	for state, ok := range map[string]bool{
+		airvantage.OperationCancelled: false,
+		airvantage.OperationFailed:    false,
+		airvantage.OperationFinished:  true,
+	} {
+		if err = srv.SetOperation(opUID, state, airvantage.OperationCounters{}); err != nil {
+			t.Fatal(err)
+		}
+		op, err := av.AwaitOperation(opUID, time.Second)
+		if (err == nil) != ok || errors.Is(err, airvantage.ErrWaitFinishedOperationTimeout) || op.State != state {
+			t.Errorf("%s: unexpected result %v", state, err)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package airvantage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// compatibilityBatchSize is the number of systems looked up per request, to
+// keep the URLs short.
+const compatibilityBatchSize = 100
+
+// Compatibility statuses of a system with an application.
+const (
+	// CompatibilityUpToDate: the revision of the application is installed.
+	CompatibilityUpToDate = "UP_TO_DATE"
+	// CompatibilityUpgrade: another revision of the application is installed.
+	CompatibilityUpgrade = "UPGRADE"
+	// CompatibilityInstall: the application is not installed.
+	CompatibilityInstall = "INSTALL"
+	// CompatibilityIncompatible: the application cannot be installed, for
+	// example a firmware of another type than the installed one.
+	CompatibilityIncompatible = "INCOMPATIBLE"
+)
+
+// SystemCompatibility is the compatibility of a system with an application.
+type SystemCompatibility struct {
+	SystemUID string
+	Status    string
+	// Installed is the installed application of the same type, or the
+	// firmware of another type for an incompatible firmware.
+	Installed *Application
+	// Reason explains an incompatibility.
+	Reason string
+}
+
+// CompatibilityReport is the outcome of CheckCompatibility.
+type CompatibilityReport struct {
+	Application *Application
+	Systems     []SystemCompatibility
+}
+
+// Installable returns the UIDs of the systems on which the application can
+// be installed or upgraded.
+func (r *CompatibilityReport) Installable() []string {
+	var uids []string
+	for _, sys := range r.Systems {
+		if sys.Status == CompatibilityUpgrade || sys.Status == CompatibilityInstall {
+			uids = append(uids, sys.SystemUID)
+		}
+	}
+	return uids
+}
+
+// CheckCompatibility compares an application with the applications installed
+// on the systems, to tell which ones are up to date, need an upgrade or an
+// install, or are incompatible. A firmware is incompatible with the systems
+// running a firmware of another type. The systems are reported in the given
+// order, the unknown ones being incompatible. They are looked up by batches
+// of 100.
+func (av *AirVantage) CheckCompatibility(appUID string, systemUIDs []string) (*CompatibilityReport, error) {
+	return av.CheckCompatibilityContext(context.Background(), appUID, systemUIDs)
+}
+
+// CheckCompatibilityContext is like CheckCompatibility but uses ctx for the API calls.
+func (av *AirVantage) CheckCompatibilityContext(ctx context.Context, appUID string, systemUIDs []string) (*CompatibilityReport, error) {
+	app, err := av.GetApplicationContext(ctx, appUID)
+	if err != nil {
+		return nil, err
+	}
+
+	systems := map[string]*System{}
+	for batch := range slices.Chunk(systemUIDs, compatibilityBatchSize) {
+		q := NewSystemQuery().UIDs(batch...).Fields("uid", "applications")
+		for sys, err := range av.AllSystemsByQuery(ctx, q) {
+			if err != nil {
+				return nil, err
+			}
+			systems[sys.UID] = &sys
+		}
+	}
+
+	report := &CompatibilityReport{Application: app}
+	for _, uid := range systemUIDs {
+		sys, ok := systems[uid]
+		if !ok {
+			report.Systems = append(report.Systems, SystemCompatibility{SystemUID: uid, Status: CompatibilityIncompatible, Reason: "system not found"})
+			continue
+		}
+		report.Systems = append(report.Systems, compatibility(app, sys))
+	}
+	return report, nil
+}
+
+// compatibility compares an application with the applications installed on a system.
+func compatibility(app *Application, sys *System) SystemCompatibility {
+	res := SystemCompatibility{SystemUID: sys.UID, Status: CompatibilityInstall}
+
+	var firmware *Application
+	for _, installed := range sys.Applications {
+		if installed == nil {
+			continue
+		}
+		if installed.Type == app.Type {
+			res.Installed = installed
+			if installed.Revision == app.Revision {
+				res.Status = CompatibilityUpToDate
+				return res
+			}
+			res.Status = CompatibilityUpgrade
+		} else if installed.Category == ApplicationFirmware {
+			firmware = installed
+		}
+	}
+
+	// a firmware only replaces a firmware of the same type
+	if app.Category == ApplicationFirmware && res.Installed == nil && firmware != nil {
+		res.Status, res.Installed = CompatibilityIncompatible, firmware
+		res.Reason = fmt.Sprintf("firmware %s installed", firmware.Type)
+	}
+	return res
+}
@@ -0,0 +1,77 @@
+package airvantage_test
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	srv, av := newServer(t)
+
+	fw := srv.AddApplication(airvantage.Application{Type: "fx30.fw", Revision: "2.0", Category: airvantage.ApplicationFirmware})
+	installed := func(typ, rev string) []*airvantage.Application {
+		return []*airvantage.Application{{Type: typ, Revision: rev, Category: airvantage.ApplicationFirmware}}
+	}
+	upToDate := srv.AddSystem(airvantage.System{Applications: installed("fx30.fw", "2.0")})
+	outdated := srv.AddSystem(airvantage.System{Applications: installed("fx30.fw", "1.0")})
+	other := srv.AddSystem(airvantage.System{Applications: installed("wp77.fw", "1.0")})
+	blank := srv.AddSystem(airvantage.System{})
+
+	report, err := av.CheckCompatibility(fw.UID, []string{upToDate.UID, outdated.UID, other.UID, blank.UID, "unknown"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		airvantage.CompatibilityUpToDate,
+		airvantage.CompatibilityUpgrade,
+		airvantage.CompatibilityIncompatible,
+		airvantage.CompatibilityInstall,
+		airvantage.CompatibilityIncompatible,
+	}
+	for i, sys := range report.Systems {
+		if sys.Status != expected[i] {
+			t.Errorf("system %d: expected %s, got %s (%s)", i, expected[i], sys.Status, sys.Reason)
+		}
+	}
+	if uids := report.Installable(); !slices.Equal(uids, []string{outdated.UID, blank.UID}) {
+		t.Errorf("invalid installable systems %v", uids)
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestCheckCompatibilityBatches(t *testing.T) {
+	var lookups int
+	srv, av := newServer(t, airvantage.WithTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/api/v1/systems" {
+			lookups++
+			if uids := strings.Split(req.URL.Query().Get("uid"), ","); len(uids) > 100 {
+				t.Errorf("%d systems looked up at once", len(uids))
+			}
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})))
+
+	app := srv.AddApplication(airvantage.Application{Type: "app", Revision: "1.0"})
+	var uids []string
+	for range 250 {
+		uids = append(uids, srv.AddSystem(airvantage.System{}).UID)
+	}
+
+	report, err := av.CheckCompatibility(app.UID, uids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lookups != 3 || !slices.Equal(report.Installable(), uids) {
+		t.Errorf("expected 250 installable systems in 3 lookups, got %d in %d", len(report.Installable()), lookups)
+	}
+}
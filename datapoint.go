@@ -0,0 +1,90 @@
+package airvantage
+
+import (
+	"cmp"
+	"encoding/json"
+	"slices"
+	"strconv"
+)
+
+// Float64 returns the value if it is a number.
+func (v TsValueV2) Float64() (float64, bool) {
+	switch value := v.Value.(type) {
+	case float64:
+		return value, true
+	case float32:
+		return float64(value), true
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	case json.Number:
+		f, err := value.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// Str returns the value if it is a string.
+func (v TsValueV2) Str() (string, bool) {
+	s, ok := v.Value.(string)
+	return s, ok
+}
+
+// Bool returns the value if it is a boolean.
+func (v TsValueV2) Bool() (bool, bool) {
+	b, ok := v.Value.(bool)
+	return b, ok
+}
+
+// Text formats the value, whatever its type. A nil value gives an empty string.
+func (v TsValueV2) Text() string {
+	switch value := v.Value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case bool:
+		return strconv.FormatBool(value)
+	}
+	if f, ok := v.Float64(); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	js, _ := json.Marshal(v.Value)
+	return string(js)
+}
+
+// DataRow is a datapoint of a DataAggregate, with its system and data.
+type DataRow struct {
+	SystemUID string
+	DataID    string
+	Datapoint
+}
+
+// Series returns the datapoints of a data of a system, the oldest first.
+func (d DataAggregate) Series(systemUID, dataID string) Series {
+	series := Series(slices.Clone(d[systemUID][dataID]))
+	slices.SortStableFunc(series, compareTimestamps)
+	return series
+}
+
+// Rows flattens the aggregate, sorted by system, data ID and time.
+func (d DataAggregate) Rows() []DataRow {
+	var rows []DataRow
+	for systemUID, data := range d {
+		for dataID, points := range data {
+			for _, point := range points {
+				rows = append(rows, DataRow{systemUID, dataID, point})
+			}
+		}
+	}
+
+	slices.SortStableFunc(rows, func(a, b DataRow) int {
+		return cmp.Or(
+			cmp.Compare(a.SystemUID, b.SystemUID),
+			cmp.Compare(a.DataID, b.DataID),
+			cmp.Compare(a.Timestamp, b.Timestamp),
+		)
+	})
+	return rows
+}
@@ -0,0 +1,60 @@
+package airvantage_test
+
+import (
+	"testing"
+	"time"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+func TestExportDataFromDevices(t *testing.T) {
+	srv, av := newServer(t)
+
+	sys1 := srv.AddSystem(airvantage.System{Name: "sys1"})
+	sys2 := srv.AddSystem(airvantage.System{Name: "sys2"})
+	srv.SetData(sys1.UID, "temperature", airvantage.TsValueV2{Timestamp: 2000, Value: 21.5}, airvantage.TsValueV2{Timestamp: 1000, Value: 20})
+	srv.SetData(sys1.UID, "door", airvantage.TsValueV2{Timestamp: 1000, Value: true})
+	srv.SetData(sys2.UID, "firmware", airvantage.TsValueV2{Timestamp: 1500, Value: "1.2.3"})
+
+	data, err := av.ExportDataFromDevices("", "", time.UnixMilli(0), time.UnixMilli(3000))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	series := data.Series(sys1.UID, "temperature")
+	if len(series) != 2 || series[0].Timestamp != 1000 {
+		t.Fatalf("expected 2 sorted temperatures, got: %+v", series)
+	}
+	if f, ok := series[1].Float64(); !ok || f != 21.5 {
+		t.Fatalf("expected a numeric value, got: %+v", series[1])
+	}
+	if _, ok := series[1].Str(); ok {
+		t.Fatalf("expected a non string value, got: %+v", series[1])
+	}
+
+	rows := data.Rows()
+	expected := []struct {
+		system, data, text string
+		ts                 airvantage.AVTime
+	}{
+		{sys1.UID, "door", "true", 1000},
+		{sys1.UID, "temperature", "20", 1000},
+		{sys1.UID, "temperature", "21.5", 2000},
+		{sys2.UID, "firmware", "1.2.3", 1500},
+	}
+	if len(rows) != len(expected) {
+		t.Fatalf("expected %d rows, got: %+v", len(expected), rows)
+	}
+	for i, row := range rows {
+		if e := expected[i]; row.SystemUID != e.system || row.DataID != e.data || row.Text() != e.text || row.Timestamp != e.ts {
+			t.Errorf("row %d: expected %+v, got: %+v", i, e, row)
+		}
+	}
+
+	if b, ok := rows[0].Bool(); !ok || !b {
+		t.Fatalf("expected a boolean value, got: %+v", rows[0])
+	}
+	if s, ok := rows[3].Str(); !ok || s != "1.2.3" {
+		t.Fatalf("expected a string value, got: %+v", rows[3])
+	}
+}
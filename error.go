@@ -1,22 +1,101 @@
 package airvantage
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 )
 
+// Errors matched with errors.Is by the *APIError of the corresponding HTTP status.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+)
+
+// APIError represents errors returned by the API.
+type APIError struct {
+	// HTTP status code of the response.
+	StatusCode int
+	// Method and URL of the request, with the secret parameters masked.
+	Method string
+	URL    string
+	// AirVantage error code, for example "system.not.found". It may be empty.
+	Code string
+	// Parameters of the error code.
+	Parameters []string
+	// Request ID of the response, useful for the AirVantage support.
+	RequestID string
+
+	// path of the request, the AvError.Path
+	path string
+	err  error
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Code != "" {
+		msg += ": " + e.Code
+	}
+	if len(e.Parameters) > 0 {
+		msg += " (" + strings.Join(e.Parameters, ", ") + ")"
+	}
+	if e.err != nil {
+		msg += ": " + e.err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error, if any.
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// Is matches the sentinel error of the status code.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+// As converts to an *AvError for the callers using errors.As. The type
+// assertions err.(*AvError) no longer match.
+func (e *APIError) As(target any) bool {
+	if avErr, ok := target.(**AvError); ok {
+		*avErr = &AvError{Path: e.path, Code: e.Code, Parameters: strings.Join(e.Parameters, ", ")}
+		return true
+	}
+	return false
+}
+
 // AvError represents errors returned by the API.
+//
+// Deprecated: use *APIError, which also carries the HTTP status.
 type AvError struct {
 	Path       string
 	Code       string
 	Parameters string
 }
 
-func avError(action, code string, parameters []string) error {
-	params := strings.Join(parameters, ", ")
-	return &AvError{Path: action, Code: code, Parameters: params}
-}
-
 func (e *AvError) Error() string {
 	return fmt.Sprintf("%s  %s: %s", e.Path, e.Code, e.Parameters)
 }
@@ -0,0 +1,57 @@
+package airvantage
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAPIError(t *testing.T) {
+	av := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req-1")
+		switch r.URL.Path {
+		case "/api/v1/systems/missing":
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"error":"system.not.found","errorParameters":["missing"]}`)
+		case "/api/v1/operations/forbidden/unsignedpayload":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			// no error code in the body
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, `{}`)
+		}
+	}, WithRetryPolicy(RetryPolicy{}))
+
+	_, err := av.FindSystemByUID("missing")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an *APIError, got: %v", err)
+	}
+	if !errors.Is(err, ErrNotFound) || errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected a not found error, got: %v", err)
+	}
+	if apiErr.StatusCode != 404 || apiErr.Method != "GET" || apiErr.Code != "system.not.found" ||
+		len(apiErr.Parameters) != 1 || apiErr.RequestID != "req-1" {
+		t.Fatalf("invalid error: %+v", apiErr)
+	}
+
+	var avErr *AvError
+	if !errors.As(err, &avErr) || avErr.Code != "system.not.found" || avErr.Path != "/api/v1/systems/missing" {
+		t.Fatalf("expected a compatible *AvError, got: %+v", avErr)
+	}
+
+	if _, err := av.GetOperationUnsignedPayload("forbidden"); !errors.Is(err, ErrForbidden) {
+		t.Fatalf("expected: %v, got: %v", ErrForbidden, err)
+	}
+
+	if _, err := av.GetOperation("uid"); !errors.Is(err, ErrBadRequest) {
+		t.Fatalf("expected: %v, got: %v", ErrBadRequest, err)
+	}
+
+	_, err = av.GetSystemSecurityInfo("secret", "id", "IMEI", "LWM2M")
+	if !errors.As(err, &apiErr) || strings.Contains(apiErr.URL, "secret") {
+		t.Fatalf("expected a masked URL, got: %v", err)
+	}
+}
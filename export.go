@@ -0,0 +1,191 @@
+package airvantage
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// A RowWriter receives the rows of a streaming export. Close flushes the
+// rows written and does not close the underlying writer.
+type RowWriter interface {
+	WriteRow(row DataRow) error
+	Close() error
+}
+
+// csvRowWriter writes the rows as CSV.
+type csvRowWriter struct {
+	w      *csv.Writer
+	header bool
+}
+
+// NewCSVRowWriter returns a RowWriter writing CSV records, with a
+// "system,data,timestamp,value" header.
+func NewCSVRowWriter(w io.Writer) RowWriter {
+	return &csvRowWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvRowWriter) WriteRow(row DataRow) error {
+	if !c.header {
+		c.header = true
+		if err := c.w.Write([]string{"system", "data", "timestamp", "value"}); err != nil {
+			return err
+		}
+	}
+	return c.w.Write([]string{row.SystemUID, row.DataID, strconv.FormatInt(int64(row.Timestamp), 10), row.Text()})
+}
+
+func (c *csvRowWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// ndjsonRowWriter writes the rows as newline-delimited JSON.
+type ndjsonRowWriter struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONRowWriter returns a RowWriter writing a JSON object per line, with
+// the "system", "data", "ts" and "v" fields.
+func NewNDJSONRowWriter(w io.Writer) RowWriter {
+	return &ndjsonRowWriter{enc: json.NewEncoder(w)}
+}
+
+func (n *ndjsonRowWriter) WriteRow(row DataRow) error {
+	return n.enc.Encode(struct {
+		SystemUID string `json:"system"`
+		DataID    string `json:"data"`
+		Datapoint
+	}{row.SystemUID, row.DataID, row.Datapoint})
+}
+
+func (n *ndjsonRowWriter) Close() error {
+	return nil
+}
+
+// ExportOptions configures ExportFleetData.
+type ExportOptions struct {
+	// DataIDs selects the exported data, all by default.
+	DataIDs []string
+	// Chunk is the duration of the time window of each request, 1 hour by
+	// default and at least 1 millisecond. Reduce it if the server rejects
+	// too large responses.
+	Chunk time.Duration
+	// OnCheckpoint is called after each chunk with the date up to which all
+	// the data was exported, to resume the export from there after a failure.
+	OnCheckpoint func(exported AVTime)
+}
+
+// ExportFleetData streams the data of all the systems of a company between
+// from and to, included, to w, without buffering the whole response. The
+// window is split into chunks fetched one after the other. The rows of a
+// chunk are grouped by system and data. opts may be nil.
+//
+// It returns the date up to which all the data was exported, even with an
+// error. To resume an interrupted export, call it again from the next
+// millisecond: the rows of the failed chunk may be written again.
+// w is not closed.
+func (av *AirVantage) ExportFleetData(companyUID string, from, to time.Time, w RowWriter, opts *ExportOptions) (AVTime, error) {
+	return av.ExportFleetDataContext(context.Background(), companyUID, from, to, w, opts)
+}
+
+// ExportFleetDataContext is like ExportFleetData but uses ctx for the API calls.
+func (av *AirVantage) ExportFleetDataContext(ctx context.Context, companyUID string, from, to time.Time, w RowWriter, opts *ExportOptions) (AVTime, error) {
+	var o ExportOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Chunk == 0 {
+		o.Chunk = time.Hour
+	}
+	if o.Chunk < time.Millisecond {
+		return 0, fmt.Errorf("invalid chunk duration %v", o.Chunk)
+	}
+	if to.Before(from) {
+		return 0, fmt.Errorf("date range ends before it starts")
+	}
+
+	start, end := NewAVTime(from), NewAVTime(to)
+	exported := start - 1
+	chunk := AVTime(o.Chunk.Milliseconds())
+	for ; start <= end; start += chunk {
+		chunkEnd := min(start+chunk-1, end)
+		if err := av.exportChunk(ctx, companyUID, o.DataIDs, start, chunkEnd, w); err != nil {
+			return exported, err
+		}
+
+		exported = chunkEnd
+		if o.OnCheckpoint != nil {
+			o.OnCheckpoint(exported)
+		}
+	}
+	return exported, nil
+}
+
+// exportChunk decodes the fleet data of a window, datapoint by datapoint.
+func (av *AirVantage) exportChunk(ctx context.Context, companyUID string, dataIDs []string, from, to AVTime, w RowWriter) error {
+	params := []any{"targetIds", companyUID, "from", from, "to", to}
+	if len(dataIDs) > 0 {
+		params = append(params, "dataIds", joinStrings(dataIDs))
+	}
+	resp, err := av.get(ctx, "systems/data/fleet", params...)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return av.parseError(resp)
+	}
+
+	// {"<system UID>": {"<data ID>": [{"ts": 0, "v": 0}, ...]}}
+	dec := json.NewDecoder(resp.Body)
+	return decodeObject(dec, func(systemUID string) error {
+		return decodeObject(dec, func(dataID string) error {
+			if err := expectDelim(dec, '['); err != nil {
+				return err
+			}
+			for dec.More() {
+				row := DataRow{SystemUID: systemUID, DataID: dataID}
+				if err := dec.Decode(&row.Datapoint); err != nil {
+					return err
+				}
+				if err := w.WriteRow(row); err != nil {
+					return err
+				}
+			}
+			return expectDelim(dec, ']')
+		})
+	})
+}
+
+// decodeObject decodes a JSON object, calling field to decode the value of each key.
+func decodeObject(dec *json.Decoder, field func(key string) error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if err = field(tok.(string)); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("invalid fleet data: expected '%v', got '%v'", delim, tok)
+	}
+	return nil
+}
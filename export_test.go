@@ -0,0 +1,101 @@
+package airvantage_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+	"github.com/AirVantage/airvantage-api-go/avtest"
+)
+
+func TestExportFleetData(t *testing.T) {
+	srv, av := newServer(t)
+	ctx := context.Background()
+
+	sys := srv.AddSystem(airvantage.System{Name: "sys"})
+	var values []airvantage.TsValueV2
+	for i := range 10 {
+		values = append(values, airvantage.TsValueV2{Timestamp: airvantage.AVTime(i * 1000), Value: i})
+	}
+	srv.SetData(sys.UID, "counter", values...)
+	srv.SetData(sys.UID, "name", airvantage.TsValueV2{Timestamp: 500, Value: "a,b"})
+
+	var buf bytes.Buffer
+	w := airvantage.NewCSVRowWriter(&buf)
+	var checkpoints []airvantage.AVTime
+	exported, err := av.ExportFleetDataContext(ctx, "", time.UnixMilli(0), time.UnixMilli(9999), w, &airvantage.ExportOptions{
+		DataIDs:      []string{"counter", "name"},
+		Chunk:        4 * time.Second,
+		OnCheckpoint: func(exported airvantage.AVTime) { checkpoints = append(checkpoints, exported) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if exported != 9999 || len(checkpoints) != 3 || checkpoints[0] != 3999 {
+		t.Fatalf("expected 3 chunks up to 9999, got: %v, %v", exported, checkpoints)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 12 || lines[0] != "system,data,timestamp,value" || !strings.Contains(buf.String(), sys.UID+`,name,500,"a,b"`) {
+		t.Fatalf("unexpected CSV export:\n%s", buf.String())
+	}
+
+	// resume after a failure
+	buf.Reset()
+	w = airvantage.NewNDJSONRowWriter(&buf)
+	exported, err = av.ExportFleetDataContext(ctx, "", time.UnixMilli(0), time.UnixMilli(9999), w, &airvantage.ExportOptions{
+		DataIDs: []string{"counter"},
+		Chunk:   4 * time.Second,
+		OnCheckpoint: func(airvantage.AVTime) {
+			srv.InjectFault(avtest.Fault{Path: "/api/v1/systems/data/fleet", Status: http.StatusInternalServerError, Times: 1})
+		},
+	})
+	if err == nil || exported != 3999 {
+		t.Fatalf("expected an error after the first chunk, got: %v, %v", exported, err)
+	}
+	if _, err = av.ExportFleetDataContext(ctx, "", exported.Time().Add(time.Millisecond), time.UnixMilli(9999), w, &airvantage.ExportOptions{DataIDs: []string{"counter"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var ts []airvantage.AVTime
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var row struct {
+			System string            `json:"system"`
+			Data   string            `json:"data"`
+			TS     airvantage.AVTime `json:"ts"`
+			V      float64           `json:"v"`
+		}
+		if err = json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			t.Fatal(err)
+		}
+		if row.System != sys.UID || row.Data != "counter" || row.V != float64(row.TS/1000) {
+			t.Fatalf("invalid row: %s", scanner.Bytes())
+		}
+		ts = append(ts, row.TS)
+	}
+	if len(ts) != 10 {
+		t.Fatalf("expected 10 rows once resumed, got: %v", ts)
+	}
+}
+
+func TestExportFleetDataChunk(t *testing.T) {
+	_, av := newServer(t)
+
+	for _, chunk := range []time.Duration{time.Microsecond, -time.Hour} {
+		w := airvantage.NewNDJSONRowWriter(&bytes.Buffer{})
+		_, err := av.ExportFleetData("", time.UnixMilli(0), time.UnixMilli(9999), w, &airvantage.ExportOptions{Chunk: chunk})
+		if err == nil {
+			t.Errorf("expected an error for a chunk of %v", chunk)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package airvantage
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// AggregateFunc aggregates the values of a data over each sampling interval.
+type AggregateFunc string
+
+const (
+	AggregateMin   AggregateFunc = "min"
+	AggregateMax   AggregateFunc = "max"
+	AggregateMean  AggregateFunc = "mean"
+	AggregateSum   AggregateFunc = "sum"
+	AggregateCount AggregateFunc = "count"
+)
+
+var aggregateFuncs = []AggregateFunc{AggregateMin, AggregateMax, AggregateMean, AggregateSum, AggregateCount}
+
+const (
+	// rawDataPageSize is the number of raw datapoints fetched per request.
+	rawDataPageSize = 1000
+	// maxAggregatedPoints is the number of aggregated values fetched per request.
+	maxAggregatedPoints = 1000
+)
+
+// Series are the values of a data over time, the oldest first.
+type Series []TsValueV2
+
+// GetRawData returns the datapoints of a system between from and to,
+// included, by data ID. Long ranges are fetched in several requests.
+func (av *AirVantage) GetRawData(systemUID string, dataIDs []string, from, to time.Time) (map[string]Series, error) {
+	return av.GetRawDataContext(context.Background(), systemUID, dataIDs, from, to)
+}
+
+// GetRawDataContext is like GetRawData but uses ctx for the API calls.
+func (av *AirVantage) GetRawDataContext(ctx context.Context, systemUID string, dataIDs []string, from, to time.Time) (map[string]Series, error) {
+	if err := checkHistoryRange(dataIDs, from, to); err != nil {
+		return nil, err
+	}
+
+	res := map[string]Series{}
+	for _, id := range dataIDs {
+		var series Series
+		start, seen := NewAVTime(from), 0
+		for {
+			// the pages resume at the newest timestamp received, which only
+			// works if each page holds the oldest points left
+			page, err := av.getHistory(ctx, "systems/data/raw", "targetIds", systemUID, "dataIds", id,
+				"from", start, "to", NewAVTime(to), "size", rawDataPageSize, "orderBy", "timestamp:asc")
+			if err != nil {
+				return nil, err
+			}
+			points := page[id]
+			slices.SortStableFunc(points, compareTimestamps)
+
+			// skip the points of the start timestamp received with the previous page
+			skip := 0
+			for skip < len(points) && skip < seen && points[skip].Timestamp == start {
+				skip++
+			}
+			series = append(series, points[skip:]...)
+			if len(points) < rawDataPageSize {
+				break
+			}
+
+			last := points[len(points)-1].Timestamp
+			if last == start {
+				return nil, fmt.Errorf("more than %d datapoints of '%s' at %d, they cannot be paged", rawDataPageSize, id, start)
+			}
+			start, seen = last, 0
+			for i := len(points) - 1; i >= 0 && points[i].Timestamp == last; i-- {
+				seen++
+			}
+		}
+		res[id] = series
+	}
+	return res, nil
+}
+
+// GetAggregatedData returns the values of the data of a system between from
+// and to, aggregated with fn over each interval, by data ID. Long ranges are
+// fetched in several requests.
+func (av *AirVantage) GetAggregatedData(systemUID string, dataIDs []string, from, to time.Time, fn AggregateFunc, interval time.Duration) (map[string]Series, error) {
+	return av.GetAggregatedDataContext(context.Background(), systemUID, dataIDs, from, to, fn, interval)
+}
+
+// GetAggregatedDataContext is like GetAggregatedData but uses ctx for the API calls.
+func (av *AirVantage) GetAggregatedDataContext(ctx context.Context, systemUID string, dataIDs []string, from, to time.Time, fn AggregateFunc, interval time.Duration) (map[string]Series, error) {
+	if err := checkHistoryRange(dataIDs, from, to); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(aggregateFuncs, fn) {
+		return nil, fmt.Errorf("unknown aggregate function '%s'", fn)
+	}
+	// the API takes the interval in milliseconds
+	if interval < time.Millisecond || interval%time.Millisecond != 0 {
+		return nil, fmt.Errorf("invalid sampling interval %v, not a positive number of milliseconds", interval)
+	}
+
+	res := map[string]Series{}
+	// past about 106 days, the window overflows and covers any range anyway
+	window := time.Duration(math.MaxInt64)
+	if interval <= math.MaxInt64/maxAggregatedPoints {
+		window = interval * maxAggregatedPoints
+	}
+	for start := from; !start.After(to); start = start.Add(window) {
+		end := start.Add(window - time.Millisecond)
+		if end.After(to) {
+			end = to
+		}
+		page, err := av.getHistory(ctx, "systems/data/aggregated", "targetIds", systemUID, "dataIds", strings.Join(dataIDs, ","),
+			"from", NewAVTime(start), "to", NewAVTime(end), "fn", fn, "interval", interval.Milliseconds())
+		if err != nil {
+			return nil, err
+		}
+		for id, points := range page {
+			res[id] = append(res[id], points...)
+		}
+	}
+	for _, series := range res {
+		slices.SortStableFunc(series, compareTimestamps)
+	}
+	return res, nil
+}
+
+// getHistory fetches datapoints by data ID.
+func (av *AirVantage) getHistory(ctx context.Context, path string, a ...any) (map[string]Series, error) {
+	resp, err := av.get(ctx, path, a...)
+	if err != nil {
+		return nil, err
+	}
+
+	page := map[string]Series{}
+	if err = av.parseResponse(resp, &page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func checkHistoryRange(dataIDs []string, from, to time.Time) error {
+	if len(dataIDs) == 0 {
+		return errors.New("no data ID")
+	}
+	if to.Before(from) {
+		return errors.New("date range ends before it starts")
+	}
+	return nil
+}
+
+func compareTimestamps(a, b TsValueV2) int {
+	return cmp.Compare(a.Timestamp, b.Timestamp)
+}
@@ -0,0 +1,169 @@
+package airvantage_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+func TestHistory(t *testing.T) {
+	srv, av := newServer(t)
+	ctx := context.Background()
+
+	sys := srv.AddSystem(airvantage.System{Name: "sys"})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var values []airvantage.TsValueV2
+	for i := range 2500 {
+		values = append(values, airvantage.TsValueV2{Timestamp: airvantage.NewAVTime(start.Add(time.Duration(i) * time.Second)), Value: i})
+	}
+	srv.SetData(sys.UID, "temperature", values...)
+
+	// raw data, fetched in 3 pages
+	raw, err := av.GetRawDataContext(ctx, sys.UID, []string{"temperature"}, start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if series := raw["temperature"]; len(series) != 2500 || series[0].Timestamp != values[0].Timestamp || series[2499].Timestamp != values[2499].Timestamp {
+		t.Fatalf("expected 2500 datapoints, got: %d", len(series))
+	}
+
+	raw, err = av.GetRawDataContext(ctx, sys.UID, []string{"temperature"}, start.Add(10*time.Second), start.Add(19*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(raw["temperature"]) != 10 {
+		t.Fatalf("expected 10 datapoints, got: %+v", raw)
+	}
+
+	// mean over 10 seconds
+	agg, err := av.GetAggregatedDataContext(ctx, sys.UID, []string{"temperature"}, start, start.Add(time.Hour), airvantage.AggregateMean, 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	series := agg["temperature"]
+	if len(series) != 250 || series[1].Value != 14.5 || series[1].Timestamp != airvantage.NewAVTime(start.Add(10*time.Second)) {
+		t.Fatalf("expected 250 means, got: %d, %+v", len(series), series[:2])
+	}
+
+	// count per second, in 3 windows
+	agg, err = av.GetAggregatedDataContext(ctx, sys.UID, []string{"temperature"}, start, start.Add(time.Hour), airvantage.AggregateCount, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if series = agg["temperature"]; len(series) != 2500 || series[2499].Value != 1.0 {
+		t.Fatalf("expected 2500 counts, got: %d", len(series))
+	}
+
+	// a yearly interval fits in a single window
+	year := 365 * 24 * time.Hour
+	agg, err = av.GetAggregatedDataContext(ctx, sys.UID, []string{"temperature"}, start.Add(-year), start.Add(year), airvantage.AggregateCount, year)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if series = agg["temperature"]; len(series) != 1 || series[0].Value != 2500.0 {
+		t.Fatalf("expected a yearly count, got: %+v", series)
+	}
+
+	if _, err = av.GetAggregatedDataContext(ctx, sys.UID, []string{"temperature"}, start, start.Add(time.Hour), "median", time.Second); err == nil {
+		t.Fatal("expected an error for an unknown function")
+	}
+	for _, interval := range []time.Duration{time.Microsecond, 1500 * time.Microsecond} {
+		if _, err = av.GetAggregatedDataContext(ctx, sys.UID, []string{"temperature"}, start, start.Add(time.Hour), airvantage.AggregateMean, interval); err == nil {
+			t.Fatalf("expected an error for an interval of %v", interval)
+		}
+	}
+	if _, err = av.GetRawDataContext(ctx, sys.UID, []string{"temperature"}, start, start.Add(-time.Hour)); err == nil {
+		t.Fatal("expected an error for an invalid range")
+	}
+}
+
+func TestGetRawDataSharedTimestamps(t *testing.T) {
+	srv, av := newServer(t)
+	ctx := context.Background()
+	sys := srv.AddSystem(airvantage.System{Name: "sys"})
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// 3 points per millisecond: the pages end in the middle of a millisecond
+	var values []airvantage.TsValueV2
+	for i := range 2500 {
+		values = append(values, airvantage.TsValueV2{Timestamp: airvantage.NewAVTime(start.Add(time.Duration(i/3) * time.Millisecond)), Value: i})
+	}
+	srv.SetData(sys.UID, "temperature", values...)
+
+	raw, err := av.GetRawDataContext(ctx, sys.UID, []string{"temperature"}, start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	series := raw["temperature"]
+	if len(series) != len(values) {
+		t.Fatalf("expected %d datapoints, got: %d", len(values), len(series))
+	}
+	for i, point := range series {
+		if point.Value != float64(i) {
+			t.Fatalf("expected value %d at %d, got: %v", i, i, point.Value)
+		}
+	}
+
+	// a full page of a single millisecond cannot be paged
+	for i := range 1200 {
+		values[i].Timestamp = values[0].Timestamp
+	}
+	srv.SetData(sys.UID, "temperature", values...)
+	if raw, err = av.GetRawDataContext(ctx, sys.UID, []string{"temperature"}, start, start.Add(time.Hour)); err == nil {
+		t.Fatalf("expected an error, got %d datapoints", len(raw["temperature"]))
+	}
+}
+
+func TestGetRawDataDescendingPages(t *testing.T) {
+	const total = 2500
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/oauth/token" {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"access_token":"token","token_type":"bearer","expires_in":3600}`)
+			return
+		}
+		query := r.URL.Query()
+		if query.Get("orderBy") != "timestamp:asc" {
+			t.Errorf("missing ascending order: %v", r.URL.RawQuery)
+		}
+		from, _ := strconv.ParseInt(query.Get("from"), 10, 64)
+		size, _ := strconv.Atoi(query.Get("size"))
+
+		// the oldest points of the range, sent the newest first
+		var page []airvantage.TsValueV2
+		for ts := max(from, 0); ts < total && len(page) < size; ts++ {
+			page = append(page, airvantage.TsValueV2{Timestamp: airvantage.AVTime(ts), Value: ts})
+		}
+		slices.Reverse(page)
+		json.NewEncoder(w).Encode(map[string][]airvantage.TsValueV2{"temperature": page})
+	}))
+	defer srv.Close()
+
+	av, err := airvantage.NewClient(srv.URL, "id", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := av.GetRawData("sys", []string{"temperature"}, time.UnixMilli(0), time.UnixMilli(total))
+	if err != nil {
+		t.Fatal(err)
+	}
+	series := raw["temperature"]
+	if len(series) != total {
+		t.Fatalf("expected %d datapoints, got: %d", total, len(series))
+	}
+	for i, point := range series {
+		if point.Timestamp != airvantage.AVTime(i) {
+			t.Fatalf("expected timestamp %d at %d, got: %d", i, i, point.Timestamp)
+		}
+	}
+}
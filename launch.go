@@ -0,0 +1,101 @@
+package airvantage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// OperationOptions are the options common to the operation launchers.
+type OperationOptions struct {
+	// ScheduledDate delays the start of the operation.
+	ScheduledDate time.Time
+	// Timeout cancels the tasks not finished in time.
+	Timeout time.Duration
+	// Notify sends an email notification when the operation finishes.
+	Notify bool
+	// Callback is called when the operation finishes.
+	Callback string
+	// RequestConnection wakes up the devices, by SMS, so that they connect
+	// to the server without waiting for their next communication.
+	RequestConnection bool
+	// Reboot restarts the devices once the settings are applied. It is only
+	// used when applying settings.
+	Reboot bool
+}
+
+// fields returns the request fields of the options which are set.
+func (o *OperationOptions) fields() map[string]any {
+	fields := map[string]any{}
+	if o == nil {
+		return fields
+	}
+	if !o.ScheduledDate.IsZero() {
+		fields["scheduledDate"] = NewAVTime(o.ScheduledDate)
+	}
+	if o.Timeout > 0 {
+		fields["timeout"] = o.Timeout.Milliseconds()
+	}
+	if o.Notify {
+		fields["notify"] = true
+	}
+	if o.Callback != "" {
+		fields["callback"] = o.Callback
+	}
+	if o.RequestConnection {
+		fields["requestConnection"] = true
+	}
+	return fields
+}
+
+// marshalLaunch returns the JSON of a launch request body, a struct or a map,
+// with the fields of the options.
+func marshalLaunch(body any, opts *OperationOptions) ([]byte, error) {
+	js, err := json.Marshal(body)
+	if err != nil || opts == nil {
+		return js, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(js, &fields); err != nil {
+		return nil, err
+	}
+	for key, field := range opts.fields() {
+		if fields[key], err = json.Marshal(field); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// launchOperation posts an operation launch request, whose body targets the
+// systems of sel, and returns the UID of the operation.
+func (av *AirVantage) launchOperation(ctx context.Context, path string, sel SystemSelection, body any, opts *OperationOptions) (string, error) {
+	if err := sel.Validate(); err != nil {
+		return "", err
+	}
+	return av.postOperation(ctx, path, body, opts)
+}
+
+// postOperation posts an operation launch request and returns the UID of the operation.
+func (av *AirVantage) postOperation(ctx context.Context, path string, body any, opts *OperationOptions) (string, error) {
+	js, err := marshalLaunch(body, opts)
+	if err != nil {
+		return "", err
+	}
+
+	url := av.URL(path)
+	av.logger().Debug("HTTP POST", "url", url, "json", string(js))
+
+	resp, err := av.post(ctx, url, "application/json", bytes.NewReader(js))
+	if err != nil {
+		return "", err
+	}
+
+	res := struct{ Operation string }{}
+	if err = av.parseResponse(resp, &res); err != nil {
+		return "", err
+	}
+	return res.Operation, nil
+}
@@ -0,0 +1,65 @@
+package airvantage_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+func TestOperationOptions(t *testing.T) {
+	srv, av := newServer(t)
+	sys := srv.AddSystem(airvantage.System{Name: "sys"})
+
+	scheduled := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	opUID, err := av.ApplySettingsBySelection(map[string]any{"key": 1}, nil, "", airvantage.SelectSystems(sys.UID), &airvantage.OperationOptions{
+		ScheduledDate:     scheduled,
+		Timeout:           time.Hour,
+		Notify:            true,
+		Callback:          "https://example.com/callback",
+		RequestConnection: true,
+		Reboot:            true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	op, _ := srv.Operation(opUID)
+	var body struct {
+		ScheduledDate     int64  `json:"scheduledDate"`
+		Timeout           int64  `json:"timeout"`
+		Notify            bool   `json:"notify"`
+		Callback          string `json:"callback"`
+		RequestConnection bool   `json:"requestConnection"`
+		Reboot            bool   `json:"reboot"`
+		Settings          []struct {
+			Key   string `json:"key"`
+			Value int    `json:"value"`
+		} `json:"settings"`
+	}
+	if err = json.Unmarshal(op.Body, &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.ScheduledDate != scheduled.UnixMilli() || body.Timeout != time.Hour.Milliseconds() || !body.Notify ||
+		body.Callback != "https://example.com/callback" || !body.RequestConnection || !body.Reboot {
+		t.Fatalf("expected the options in the request, got: %s", op.Body)
+	}
+	if len(body.Settings) != 1 || body.Settings[0].Key != "key" || body.Settings[0].Value != 1 {
+		t.Fatalf("expected the settings in the request, got: %s", op.Body)
+	}
+
+	// no options
+	opUID, err = av.Reboot("", sys.UID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op, _ = srv.Operation(opUID)
+	var fields map[string]any
+	if err = json.Unmarshal(op.Body, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) != 2 {
+		t.Fatalf("expected only the systems and action, got: %s", op.Body)
+	}
+}
@@ -2,11 +2,12 @@ package airvantage
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
-	"log/slog"
+	"iter"
 	"mime/multipart"
 	"net/url"
 	"strings"
@@ -68,40 +69,88 @@ func (oc OperationCounters) MarshalJSON() ([]byte, error) {
 	return json.Marshal(counters)
 }
 
+// Operation types.
+const (
+	OperationTypeApplySettings        = "SYSTEM_APPLY_SETTINGS"
+	OperationTypeSendCommand          = "SYSTEM_SEND_COMMAND"
+	OperationTypeInstallApplication   = "SYSTEM_INSTALL_APPLICATION"
+	OperationTypeUninstallApplication = "SYSTEM_UNINSTALL_APPLICATION"
+	OperationTypeReboot               = "SYSTEM_REBOOT"
+	OperationTypeReset                = "SYSTEM_RESET"
+	OperationTypeSendFile             = "SYSTEM_SEND_FILE"
+	OperationTypeRetrieveData         = "SYSTEM_RETRIEVE_DATA"
+	OperationTypeConfigure            = "SYSTEM_CONFIGURE_COMMUNICATION"
+)
+
+// operationEndpoints are the launch endpoints of the operation types which can be relaunched.
+var operationEndpoints = map[string]string{
+	OperationTypeApplySettings:        "operations/systems/settings",
+	OperationTypeSendCommand:          "operations/systems/command",
+	OperationTypeInstallApplication:   "operations/systems/applications/install",
+	OperationTypeUninstallApplication: "operations/systems/applications/uninstall",
+	OperationTypeReboot:               "operations/systems/reboot",
+	OperationTypeReset:                "operations/systems/reset",
+	OperationTypeSendFile:             "operations/systems/file/send",
+	OperationTypeRetrieveData:         "operations/systems/data/retrieve",
+	OperationTypeConfigure:            "operations/systems/configure",
+}
+
 // An Operation descriptor.
 type Operation struct {
 	UID      string `json:"uid"`
+	Type     string `json:"type,omitempty"`
 	State    string
 	Timeout  AVTime `json:"timeoutDate,omitempty"`
 	Counters OperationCounters
+	// Parameters are the launch parameters of the operation, without the
+	// targeted systems, when the API reports them.
+	Parameters json.RawMessage `json:"parameters,omitempty"`
 }
 
-// AwaitOperation blocks until the operation is finished or expired.
+// AwaitOperation blocks until the operation is finished, or expired. It
+// polls the operation every 5 seconds, and a timeout of 0 polls it once.
+// It returns an error if the operation is not FINISHED: cancelled, failed,
+// or ErrWaitFinishedOperationTimeout when still running at the timeout.
+// See AwaitOperationResult for more options.
 func (av *AirVantage) AwaitOperation(opUID string, timeout time.Duration) (*Operation, error) {
-	start := time.Now()
-	for {
-		op, err := av.GetOperation(opUID)
-		if err != nil {
+	return av.AwaitOperationContext(context.Background(), opUID, timeout)
+}
+
+// AwaitOperationContext is like AwaitOperation but uses ctx for the API calls.
+func (av *AirVantage) AwaitOperationContext(ctx context.Context, opUID string, timeout time.Duration) (*Operation, error) {
+	var op *Operation
+	if timeout <= 0 {
+		var err error
+		if op, err = av.GetOperationContext(ctx, opUID); err != nil {
 			return nil, err
 		}
-
-		slog.Debug("Waiting Operation", "uid", op)
-
-		if op.State == "FINISHED" {
-			return op, nil
+	} else {
+		res, err := av.AwaitOperationResultContext(ctx, opUID, &AwaitOptions{Timeout: timeout, Interval: 5 * time.Second, Multiplier: 1})
+		if res == nil {
+			return nil, err
 		}
-
-		if time.Since(start) > timeout {
-			return op, ErrWaitFinishedOperationTimeout
+		if op = &res.Operation; err != nil {
+			return op, err
 		}
+	}
 
-		time.Sleep(5 * time.Second)
+	switch {
+	case op.State == OperationFinished:
+		return op, nil
+	case op.IsTerminal():
+		return op, fmt.Errorf("operation %s %s", opUID, op.State)
 	}
+	return op, ErrWaitFinishedOperationTimeout
 }
 
 // GetOperation retrieves details about an Operation.
 func (av *AirVantage) GetOperation(uid string) (*Operation, error) {
-	resp, err := av.get("operations/" + uid)
+	return av.GetOperationContext(context.Background(), uid)
+}
+
+// GetOperationContext is like GetOperation but uses ctx for the API calls.
+func (av *AirVantage) GetOperationContext(ctx context.Context, uid string) (*Operation, error) {
+	resp, err := av.get(ctx, "operations/"+uid)
 	if err != nil {
 		return nil, err
 	}
@@ -120,7 +169,14 @@ func (av *AirVantage) GetOperation(uid string) (*Operation, error) {
 // - fields is a comma-separated list of fields to return (optional)
 // - orderBy is a comma-separated list of fields to order the results (optional)
 // You can limit the number of results (100 by default) by adding a criteria 'size'.
+// It only returns the first page of results, use AllOperations to get all of them.
 func (av *AirVantage) FindOperations(criteria url.Values, fields, orderBy string) ([]Operation, error) {
+	return av.FindOperationsContext(context.Background(), criteria, fields, orderBy)
+}
+
+// FindOperationsContext is like FindOperations but uses ctx for the API calls.
+func (av *AirVantage) FindOperationsContext(ctx context.Context, criteria url.Values, fields, orderBy string) ([]Operation, error) {
+	criteria = cloneValues(criteria)
 	if fields != "" {
 		criteria.Set("fields", fields)
 	}
@@ -128,7 +184,7 @@ func (av *AirVantage) FindOperations(criteria url.Values, fields, orderBy string
 		criteria.Set("orderBy", orderBy)
 	}
 
-	resp, err := av.getWithParams("operations", criteria)
+	resp, err := av.getWithParams(ctx, "operations", criteria)
 	if err != nil {
 		return nil, err
 	}
@@ -143,10 +199,26 @@ func (av *AirVantage) FindOperations(criteria url.Values, fields, orderBy string
 	return page.Items, nil
 }
 
+// OperationPages returns the pages of the operations matching the criteria, see FindOperations.
+func (av *AirVantage) OperationPages(ctx context.Context, criteria url.Values, fields, orderBy string) *Pages[Operation] {
+	return newPages[Operation](ctx, av, "operations", criteria, fields, orderBy)
+}
+
+// AllOperations iterates over all the operations matching the criteria, see FindOperations.
+// Unlike FindOperations, it is not limited to one page of results.
+func (av *AirVantage) AllOperations(ctx context.Context, criteria url.Values, fields, orderBy string) iter.Seq2[Operation, error] {
+	return av.OperationPages(ctx, criteria, fields, orderBy).All()
+}
+
 // CancelOperation cancels the operation with the given UID.
 func (av *AirVantage) CancelOperation(opUID string) (*Operation, error) {
+	return av.CancelOperationContext(context.Background(), opUID)
+}
 
-	resp, err := av.client.Post(av.URL(fmt.Sprintf("operations/%s/cancel", opUID)), "application/json", bytes.NewReader([]byte{}))
+// CancelOperationContext is like CancelOperation but uses ctx for the API calls.
+func (av *AirVantage) CancelOperationContext(ctx context.Context, opUID string) (*Operation, error) {
+
+	resp, err := av.post(ctx, av.URL(fmt.Sprintf("operations/%s/cancel", opUID)), "application/json", bytes.NewReader([]byte{}))
 	if err != nil {
 		return nil, err
 	}
@@ -158,15 +230,101 @@ func (av *AirVantage) CancelOperation(opUID string) (*Operation, error) {
 	return op, nil
 }
 
+// RetryFailed relaunches a finished operation with its original parameters,
+// on the systems where its task failed or was cancelled. It fails if the
+// API does not report the parameters of the operation, use RetryFailedWith
+// then. It returns the UID of the new operation, or an empty UID if all the
+// systems succeeded.
+func (av *AirVantage) RetryFailed(opUID string) (string, error) {
+	return av.RetryFailedContext(context.Background(), opUID)
+}
+
+// RetryFailedContext is like RetryFailed but uses ctx for the API calls.
+func (av *AirVantage) RetryFailedContext(ctx context.Context, opUID string) (string, error) {
+	return av.retryFailed(ctx, opUID, nil)
+}
+
+// RetryFailedWith is like RetryFailed but relaunches the operation with the
+// given parameters, the launch body of the original operation without its
+// systems, for example:
+//
+//	map[string]any{"application": appUID}
+func (av *AirVantage) RetryFailedWith(opUID string, params any) (string, error) {
+	return av.RetryFailedWithContext(context.Background(), opUID, params)
+}
+
+// RetryFailedWithContext is like RetryFailedWith but uses ctx for the API calls.
+func (av *AirVantage) RetryFailedWithContext(ctx context.Context, opUID string, params any) (string, error) {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return "", fmt.Errorf("invalid parameters: %w", err)
+	}
+	return av.retryFailed(ctx, opUID, b)
+}
+
+// retryFailed relaunches an operation on its failed systems, with the
+// parameters reported by the API when params is nil.
+func (av *AirVantage) retryFailed(ctx context.Context, opUID string, params json.RawMessage) (string, error) {
+	op, err := av.GetOperationContext(ctx, opUID)
+	if err != nil {
+		return "", err
+	}
+	if !op.IsTerminal() {
+		return "", fmt.Errorf("operation %s is not finished: %s", opUID, op.State)
+	}
+	endpoint, ok := operationEndpoints[op.Type]
+	if !ok {
+		return "", fmt.Errorf("operation %s of type %q cannot be relaunched", opUID, op.Type)
+	}
+
+	var uids []string
+	for task, err := range av.AllTasks(ctx, opUID, TaskFailure, TaskCancelled) {
+		if err != nil {
+			return "", err
+		}
+		uids = append(uids, task.System)
+	}
+	if len(uids) == 0 {
+		return "", nil
+	}
+
+	// without its parameters, the operation would be relaunched with the
+	// systems only, for example an install without application
+	if params == nil {
+		if len(op.Parameters) == 0 {
+			return "", fmt.Errorf("operation %s does not report its launch parameters, relaunch it with RetryFailedWith", opUID)
+		}
+		params = op.Parameters
+	}
+	var body map[string]any
+	if err = json.Unmarshal(params, &body); err != nil {
+		return "", fmt.Errorf("invalid parameters of operation %s: %w", opUID, err)
+	}
+	if body == nil {
+		return "", fmt.Errorf("no parameters for operation %s", opUID)
+	}
+	sel := SelectSystems(uids...)
+	body["systems"] = sel
+
+	return av.launchOperation(ctx, endpoint, sel, body, nil)
+}
+
 // GetOperationUnsignedPayload retrieves the operation unsigned payload as a JSON string
 func (av *AirVantage) GetOperationUnsignedPayload(uid string) (string, error) {
-	resp, err := av.get(fmt.Sprintf("operations/%s/unsignedpayload", uid))
+	return av.GetOperationUnsignedPayloadContext(context.Background(), uid)
+}
+
+// GetOperationUnsignedPayloadContext is like GetOperationUnsignedPayload but uses ctx for the API calls.
+func (av *AirVantage) GetOperationUnsignedPayloadContext(ctx context.Context, uid string) (string, error) {
+	resp, err := av.get(ctx, fmt.Sprintf("operations/%s/unsignedpayload", uid))
 	if err != nil {
 		return "", err
 	}
 
-	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("invalid response when retrieveing unsignedpayload: %+v", resp)
+	defer resp.Body.Close()
+
+	if err = av.parseError(resp); err != nil {
+		return "", err
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -178,6 +336,11 @@ func (av *AirVantage) GetOperationUnsignedPayload(uid string) (string, error) {
 
 // ApproveOperation approves the operation with the given UID.
 func (av *AirVantage) ApproveOperation(opUID, base64Signature, algorithm string, certificateChain io.Reader) (err error) {
+	return av.ApproveOperationContext(context.Background(), opUID, base64Signature, algorithm, certificateChain)
+}
+
+// ApproveOperationContext is like ApproveOperation but uses ctx for the API calls.
+func (av *AirVantage) ApproveOperationContext(ctx context.Context, opUID, base64Signature, algorithm string, certificateChain io.Reader) (err error) {
 
 	values := make(map[string]io.Reader)
 	values["cert"] = certificateChain
@@ -208,9 +371,11 @@ func (av *AirVantage) ApproveOperation(opUID, base64Signature, algorithm string,
 	}
 	w.Close()
 
-	resp, err := av.client.Post(av.URL(fmt.Sprintf("operations/%s/approve", opUID)), w.FormDataContentType(), &b)
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("invalid response for approve API call: %+v", resp)
+	resp, err := av.post(ctx, av.URL(fmt.Sprintf("operations/%s/approve", opUID)), w.FormDataContentType(), &b)
+	if err != nil {
+		return err
 	}
-	return err
+	defer resp.Body.Close()
+
+	return av.parseError(resp)
 }
@@ -0,0 +1,87 @@
+package airvantage_test
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+func TestRetryFailed(t *testing.T) {
+	srv, av := newServer(t)
+
+	var uids []string
+	for range 4 {
+		uids = append(uids, srv.AddSystem(airvantage.System{Name: "sys"}).UID)
+	}
+	srv.FailSystems("timeout", uids[0], uids[2])
+
+	opUID, err := av.ConfigureCommunication("ON", 60, "", 0, uids, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the operation must be finished
+	srv.FinishOperationsAfter(-1)
+	if _, err = av.RetryFailed(opUID); err == nil {
+		t.Fatal("expected an error for an operation in progress")
+	}
+	srv.FinishOperationsAfter(1)
+	if _, err = av.AwaitOperation(opUID, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// the parameters must be reported to relaunch the operation, or given
+	if _, err = av.RetryFailed(opUID); err == nil || !strings.Contains(err.Error(), "parameters") {
+		t.Fatalf("expected an error without parameters, got %v", err)
+	}
+	if _, err = av.RetryFailedWith(opUID, nil); err == nil {
+		t.Fatal("expected an error with null parameters")
+	}
+	retryUID, err := av.RetryFailedWith(opUID, map[string]any{"heartbeat": map[string]any{"state": "OFF"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retried, ok := srv.Operation(retryUID); !ok || !slices.Equal(retried.Systems, []string{uids[0], uids[2]}) ||
+		!strings.Contains(string(retried.Body), `"state":"OFF"`) {
+		t.Fatalf("invalid operation relaunched with parameters: %+v", retried)
+	}
+
+	if err = srv.SetOperationParameters(opUID, json.RawMessage(`{"heartbeat":{"state":"ON","period":60}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	retryUID, err = av.RetryFailed(opUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retried, ok := srv.Operation(retryUID)
+	if !ok || retried.Endpoint != "systems/configure" || !slices.Equal(retried.Systems, []string{uids[0], uids[2]}) {
+		t.Fatalf("invalid relaunched operation: %+v", retried)
+	}
+
+	var body struct {
+		HeartBeat struct {
+			State  string `json:"state"`
+			Period int    `json:"period"`
+		} `json:"heartbeat"`
+	}
+	if err = json.Unmarshal(retried.Body, &body); err != nil || body.HeartBeat.State != "ON" || body.HeartBeat.Period != 60 {
+		t.Fatalf("expected the original parameters, got: %s", retried.Body)
+	}
+
+	// nothing to retry
+	opUID, err = av.Reboot("", uids[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = av.AwaitOperation(opUID, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if retryUID, err = av.RetryFailed(opUID); err != nil || retryUID != "" {
+		t.Fatalf("expected no relaunched operation, got: %q, %v", retryUID, err)
+	}
+}
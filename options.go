@@ -0,0 +1,152 @@
+package airvantage
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// An Option configures the API client returned by NewClient.
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	httpClient  *http.Client
+	transport   http.RoundTripper
+	timeout     time.Duration
+	logger      *slog.Logger
+	userAgent   string
+	company     string
+	proxy       func(*http.Request) (*url.URL, error)
+	tlsConfig   *tls.Config
+	retry       *RetryPolicy
+	limiter     *RateLimiter
+	maxInFlight int
+}
+
+// WithHTTPClient sets the HTTP client used for the API and the oAuth2 token
+// endpoint. Its transport gets wrapped to add the authentication.
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *clientOptions) {
+		o.httpClient = client
+	}
+}
+
+// WithTransport sets the transport used for the API and the oAuth2 token
+// endpoint. It takes precedence over the transport of WithHTTPClient.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(o *clientOptions) {
+		o.transport = transport
+	}
+}
+
+// WithTimeout sets the timeout of every HTTP request, including the login.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithLogger sets the logger of the client instead of the default slog logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *clientOptions) {
+		o.logger = logger
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every HTTP request.
+func WithUserAgent(userAgent string) Option {
+	return func(o *clientOptions) {
+		o.userAgent = userAgent
+	}
+}
+
+// WithCompany sets the company UID used for the API calls.
+func WithCompany(companyUID string) Option {
+	return func(o *clientOptions) {
+		o.company = companyUID
+	}
+}
+
+// WithProxy sets the proxy function of the transport, for example
+// http.ProxyURL or http.ProxyFromEnvironment.
+// It requires the transport to be an *http.Transport.
+func WithProxy(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(o *clientOptions) {
+		o.proxy = proxy
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the transport, for example to
+// trust a custom CA. It requires the transport to be an *http.Transport.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(o *clientOptions) {
+		o.tlsConfig = config
+	}
+}
+
+// baseTransport returns the transport carrying the requests, nil meaning
+// http.DefaultTransport.
+func (o *clientOptions) baseTransport() (http.RoundTripper, error) {
+	rt := o.transport
+	if rt == nil && o.httpClient != nil {
+		rt = o.httpClient.Transport
+	}
+
+	if o.proxy != nil || o.tlsConfig != nil {
+		if rt == nil {
+			rt = http.DefaultTransport
+		}
+		t, ok := rt.(*http.Transport)
+		if !ok {
+			return nil, fmt.Errorf("proxy and TLS options require an *http.Transport, got %T", rt)
+		}
+		t = t.Clone()
+		if o.proxy != nil {
+			t.Proxy = o.proxy
+		}
+		if o.tlsConfig != nil {
+			t.TLSClientConfig = o.tlsConfig
+		}
+		rt = t
+	}
+
+	if o.userAgent != "" {
+		rt = &userAgentTransport{userAgent: o.userAgent, base: rt}
+	}
+
+	return rt, nil
+}
+
+// newHTTPClient returns a copy of the configured HTTP client using the given
+// transport.
+func (o *clientOptions) newHTTPClient(transport http.RoundTripper, timeout time.Duration) *http.Client {
+	client := &http.Client{}
+	if o.httpClient != nil {
+		*client = *o.httpClient
+	}
+	client.Transport = transport
+	if timeout != 0 {
+		client.Timeout = timeout
+	}
+	return client
+}
+
+// userAgentTransport sets the User-Agent header of the requests.
+type userAgentTransport struct {
+	userAgent string
+	base      http.RoundTripper
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	uaReq := req.Clone(req.Context())
+	uaReq.Header.Set("User-Agent", t.userAgent)
+
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(uaReq)
+}
@@ -0,0 +1,91 @@
+package airvantage
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClientOptions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ua := r.Header.Get("User-Agent"); ua != "fleet-job/1.0" {
+			t.Errorf("invalid User-Agent on %s: %v", r.URL.Path, ua)
+		}
+		switch r.URL.Path {
+		case "/api/oauth/token":
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"access_token":"token","token_type":"bearer","expires_in":3600}`)
+		case "/api/v1/systems":
+			if company := r.URL.Query().Get("company"); company != "company-uid" {
+				t.Errorf("invalid company: %v", company)
+			}
+			io.WriteString(w, `{"items":[{"uid":"uid"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	var trips int
+	base := srv.Client().Transport
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		trips++
+		return base.RoundTrip(req)
+	})
+
+	var logs bytes.Buffer
+	logger := slog.New(NewSimpleLogHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	av, err := NewClient(srv.URL, "id", "secret",
+		WithTransport(transport),
+		WithUserAgent("fleet-job/1.0"),
+		WithCompany("company-uid"),
+		WithLogger(logger),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	av.Debug = true
+
+	if av.CompanyUID != "company-uid" {
+		t.Fatalf("expected: %v, got: %v", "company-uid", av.CompanyUID)
+	}
+
+	if _, err := av.FindSystemByName("name", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	// token + search
+	if trips != 2 {
+		t.Fatalf("expected 2 requests through the transport, got: %v", trips)
+	}
+	if !strings.Contains(logs.String(), "Parsing response") {
+		t.Fatalf("expected debug logs in the client logger, got: %q", logs.String())
+	}
+}
+
+func TestClientOptionsTLSRequiresTransport(t *testing.T) {
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, nil
+	})
+
+	_, err := NewClient("example.com", "id", "secret", WithTransport(transport), WithTLSConfig(&tls.Config{}))
+	if err == nil {
+		t.Fatal("expected an error with a custom RoundTripper")
+	}
+
+	if _, err := NewClient("example.com", "id", "secret", WithTLSConfig(&tls.Config{}), WithProxy(http.ProxyFromEnvironment)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,109 @@
+package airvantage
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+const defaultPageSize = 100
+
+// Pages walks through all the pages of a search using the offset and size
+// criteria. It is not safe for concurrent use.
+type Pages[T any] struct {
+	av       *AirVantage
+	ctx      context.Context
+	path     string
+	criteria url.Values
+	total    int
+}
+
+// page is the result of a paginated search.
+type page[T any] struct {
+	Items  []T `json:"items"`
+	Count  int `json:"count"`
+	Size   int `json:"size"`
+	Offset int `json:"offset"`
+}
+
+func newPages[T any](ctx context.Context, av *AirVantage, path string, criteria url.Values, fields, orderBy string) *Pages[T] {
+	copy := cloneValues(criteria)
+	if fields != "" {
+		copy.Set("fields", fields)
+	}
+	if orderBy != "" {
+		copy.Set("orderBy", orderBy)
+	}
+
+	return &Pages[T]{av: av, ctx: ctx, path: path, criteria: copy}
+}
+
+// Total returns the total number of items reported by the server. It is only
+// known once the first page has been fetched.
+func (p *Pages[T]) Total() int {
+	return p.total
+}
+
+// All returns an iterator over the items of all the pages, starting at the
+// 'offset' criteria. Each request fetches 'size' items (100 by default).
+// The iteration stops after the first error, including the cancellation of
+// the context, which is yielded with a zero item.
+func (p *Pages[T]) All() iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		size, err := intCriteria(p.criteria, "size", defaultPageSize)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		offset, err := intCriteria(p.criteria, "offset", 0)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		criteria := cloneValues(p.criteria)
+		criteria.Set("size", strconv.Itoa(size))
+
+		for {
+			if err := p.ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			criteria.Set("offset", strconv.Itoa(offset))
+			resp, err := p.av.getWithParams(p.ctx, p.path, criteria)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			var res page[T]
+			if err = p.av.parseResponse(resp, &res); err != nil {
+				yield(zero, err)
+				return
+			}
+			p.total = res.Count
+
+			for _, item := range res.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			offset += len(res.Items)
+			if len(res.Items) == 0 || (res.Count > 0 && offset >= res.Count) || (res.Count == 0 && len(res.Items) < size) {
+				return
+			}
+		}
+	}
+}
+
+func intCriteria(criteria url.Values, key string, def int) (int, error) {
+	if !criteria.Has(key) {
+		return def, nil
+	}
+	return strconv.Atoi(criteria.Get(key))
+}
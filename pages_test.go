@@ -0,0 +1,157 @@
+package airvantage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+func TestAllSystems(t *testing.T) {
+	const total = 250
+
+	requests := 0
+	av := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+		if r.URL.Query().Get("labels") != "fleet" {
+			t.Errorf("missing criteria: %v", r.URL.RawQuery)
+		}
+
+		res := page[System]{Count: total, Offset: offset, Size: size}
+		for i := offset; i < min(offset+size, total); i++ {
+			res.Items = append(res.Items, System{UID: fmt.Sprint(i)})
+		}
+		json.NewEncoder(w).Encode(res)
+	})
+
+	criteria := url.Values{"labels": {"fleet"}}
+	pages := av.SystemPages(context.Background(), criteria, "uid", "")
+
+	n := 0
+	for sys, err := range pages.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sys.UID != strconv.Itoa(n) {
+			t.Fatalf("expected: %v, got: %v", n, sys.UID)
+		}
+		n++
+	}
+
+	if n != total || pages.Total() != total || requests != 3 {
+		t.Fatalf("expected %d systems in 3 requests, got: %d (total %d) in %d requests", total, n, pages.Total(), requests)
+	}
+	if criteria.Has("fields") || criteria.Has("offset") {
+		t.Fatalf("criteria modified: %v", criteria)
+	}
+
+	// stop early
+	requests = 0
+	for range av.AllSystems(context.Background(), criteria, "", "") {
+		break
+	}
+	if requests != 1 {
+		t.Fatalf("expected 1 request, got: %v", requests)
+	}
+
+	// cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	n = 0
+	var lastErr error
+	for _, err := range av.AllSystems(ctx, criteria, "", "") {
+		if err != nil {
+			lastErr = err
+			break
+		}
+		if n++; n == 150 {
+			cancel()
+		}
+	}
+	if !errors.Is(lastErr, context.Canceled) || n != 200 {
+		t.Fatalf("expected to stop after the second page, got: %v after %d systems", lastErr, n)
+	}
+}
+
+func TestPagesStop(t *testing.T) {
+	tests := []struct {
+		name string
+		// available items, count reported by the server (0 if unknown) and
+		// request answered with an error (0 for none)
+		items, count, failAt int
+		offset               string
+		expected, requests   int
+		fails                bool
+	}{
+		{name: "count reached", items: 5, count: 5, expected: 5, requests: 3},
+		{name: "short page", items: 5, expected: 5, requests: 3},
+		{name: "full last page", items: 4, expected: 4, requests: 3},
+		{name: "empty page", items: 3, count: 10, expected: 3, requests: 3},
+		{name: "offset past count", items: 5, count: 5, offset: "6", expected: 0, requests: 1},
+		{name: "error", items: 5, count: 5, failAt: 2, expected: 2, requests: 2, fails: true},
+	}
+
+	endpoints := map[string]func(av *AirVantage, criteria url.Values) (uids []string, total int, err error){
+		"operations": func(av *AirVantage, criteria url.Values) ([]string, int, error) {
+			pages := av.OperationPages(context.Background(), criteria, "", "")
+			return collectUIDs(pages, func(op Operation) string { return op.UID })
+		},
+		"applications": func(av *AirVantage, criteria url.Values) ([]string, int, error) {
+			pages := av.ApplicationPages(context.Background(), criteria, "", "")
+			return collectUIDs(pages, func(app Application) string { return app.UID })
+		},
+	}
+
+	for endpoint, list := range endpoints {
+		for _, tt := range tests {
+			requests := 0
+			av := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests == tt.failAt {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+				size, _ := strconv.Atoi(r.URL.Query().Get("size"))
+
+				// the same items fit the operations and applications
+				res := page[Application]{Count: tt.count, Offset: offset, Size: size, Items: []Application{}}
+				for i := offset; i < min(offset+size, tt.items); i++ {
+					res.Items = append(res.Items, Application{UID: fmt.Sprint(i)})
+				}
+				json.NewEncoder(w).Encode(res)
+			})
+
+			criteria := url.Values{"size": {"2"}}
+			if tt.offset != "" {
+				criteria.Set("offset", tt.offset)
+			}
+			uids, total, err := list(av, criteria)
+
+			if (err != nil) != tt.fails || len(uids) != tt.expected || requests != tt.requests {
+				t.Errorf("%s %s: expected %d items in %d requests, got %d in %d (error %v)",
+					endpoint, tt.name, tt.expected, tt.requests, len(uids), requests, err)
+			}
+			if total != tt.count {
+				t.Errorf("%s %s: expected total %d, got %d", endpoint, tt.name, tt.count, total)
+			}
+		}
+	}
+}
+
+func collectUIDs[T any](pages *Pages[T], uid func(T) string) ([]string, int, error) {
+	var uids []string
+	for item, err := range pages.All() {
+		if err != nil {
+			return uids, pages.Total(), err
+		}
+		uids = append(uids, uid(item))
+	}
+	return uids, pages.Total(), nil
+}
@@ -0,0 +1,139 @@
+package airvantage
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// A RateLimiter is a token bucket limiting the rate of the API requests.
+// It is safe for concurrent use and can be shared between several clients.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a limiter allowing rate requests per second, with
+// bursts of at most burst requests. A rate of 0 or less does not limit the
+// requests.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	burst = max(burst, 1)
+	return &RateLimiter{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// refill adds the tokens earned since the last call. l.mu must be held.
+func (l *RateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = min(l.tokens+elapsed.Seconds()*l.rate, l.burst)
+		l.last = now
+	}
+}
+
+// delay returns how long to wait for the given token balance.
+func (l *RateLimiter) delay(tokens float64) time.Duration {
+	if tokens >= 0 || l.rate <= 0 {
+		return 0
+	}
+	return time.Duration(-tokens / l.rate * float64(time.Second))
+}
+
+// Wait blocks until a request is allowed or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	l.refill(time.Now())
+	l.tokens--
+	delay := l.delay(l.tokens)
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		// give back the reserved token
+		l.mu.Lock()
+		l.tokens = min(l.tokens+1, l.burst)
+		l.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// WaitTime returns how long a new request would currently wait.
+func (l *RateLimiter) WaitTime() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(time.Now())
+	return l.delay(l.tokens - 1)
+}
+
+// WithRateLimit limits the client to rate requests per second, with bursts of
+// at most burst requests. A rate of 0 or less does not limit the requests.
+func WithRateLimit(rate float64, burst int) Option {
+	return WithRateLimiter(NewRateLimiter(rate, burst))
+}
+
+// WithRateLimiter sets the rate limiter of the client, which may be shared
+// with other clients.
+func WithRateLimiter(limiter *RateLimiter) Option {
+	return func(o *clientOptions) {
+		o.limiter = limiter
+	}
+}
+
+// WithMaxInFlight limits the number of requests sent concurrently by the client.
+// A request leaves the slot when its response body is closed, so a streamed
+// response, such as an export, counts until it is fully read.
+func WithMaxInFlight(n int) Option {
+	return func(o *clientOptions) {
+		o.maxInFlight = n
+	}
+}
+
+// RateLimiter returns the rate limiter of the client, nil if there is none.
+func (av *AirVantage) RateLimiter() *RateLimiter {
+	return av.limiter
+}
+
+// releasingBody frees a request slot when the response body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// acquire waits for the rate limiter and a free request slot. The returned
+// function frees the slot.
+func (av *AirVantage) acquire(ctx context.Context) (func(), error) {
+	if av.limiter != nil {
+		if err := av.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if av.inFlight == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case av.inFlight <- struct{}{}:
+		return func() { <-av.inFlight }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,105 @@
+package airvantage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := NewRateLimiter(10, 2)
+
+	if d := l.WaitTime(); d != 0 {
+		t.Fatalf("expected no wait with a full bucket, got: %v", d)
+	}
+	for range 2 {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if d := l.WaitTime(); d < 50*time.Millisecond || d > 100*time.Millisecond {
+		t.Fatalf("expected to wait for about 100ms, got: %v", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected: %v, got: %v", context.Canceled, err)
+	}
+
+	start := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("expected to wait for the next token, waited: %v", elapsed)
+	}
+}
+
+func TestMaxInFlight(t *testing.T) {
+	var current, peak atomic.Int32
+	av := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		n := current.Add(1)
+		defer current.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		io.WriteString(w, `{"uid":"uid"}`)
+	}, WithMaxInFlight(2), WithRateLimit(1000, 10))
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := av.FindSystemByUID("uid"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if peak.Load() > 2 {
+		t.Fatalf("expected at most 2 requests in flight, got: %v", peak.Load())
+	}
+	if av.RateLimiter() == nil {
+		t.Fatal("expected a rate limiter")
+	}
+}
+
+func TestMaxInFlightBody(t *testing.T) {
+	av := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"uid":"uid"}`)
+	}, WithMaxInFlight(1))
+
+	resp, err := av.get(context.Background(), "systems/uid")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the slot is held until the body of the first response is closed
+	done := make(chan error)
+	go func() {
+		_, err := av.FindSystemByUID("uid")
+		done <- err
+	}()
+	select {
+	case err = <-done:
+		t.Fatalf("the second request was sent with the first body open: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	resp.Body.Close()
+	if err = <-done; err != nil {
+		t.Fatal(err)
+	}
+}
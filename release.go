@@ -0,0 +1,229 @@
+package airvantage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AirVantage/airvantage-api-go/avapp"
+)
+
+// Steps of ReleaseAndPublish.
+const (
+	StepPackage = "package"
+	StepRelease = "release"
+	StepResolve = "resolve"
+	StepLabel   = "label"
+	StepPublish = "publish"
+)
+
+// ReleaseOptions configures ReleaseAndPublish.
+type ReleaseOptions struct {
+	// Publish publishes the application once released.
+	Publish bool
+	// Labels are added to the released application.
+	Labels []string
+	// Operation configures the release and publish operations.
+	Operation *OperationOptions
+	// Await configures the wait of the operations.
+	Await *AwaitOptions
+	// KeepOnFailure keeps the released application when a later step fails,
+	// instead of deleting it.
+	KeepOnFailure bool
+}
+
+// ReleaseReport is the outcome of ReleaseAndPublish.
+type ReleaseReport struct {
+	// ReleaseOperation and PublishOperation are the UIDs of the operations
+	// launched, empty if they were not.
+	ReleaseOperation string
+	PublishOperation string
+	// Application is the released application, in its last known state.
+	Application *Application
+	// FailedStep is the step which failed, empty on success.
+	FailedStep string
+	// RolledBack tells if the released application was deleted after the failure.
+	RolledBack bool
+	// RollbackError tells why the released application could not be deleted,
+	// for example a release operation still running.
+	RollbackError error
+}
+
+// releaseEndTimeout bounds the wait of a release operation before a rollback,
+// when the await options do not have a timeout.
+const releaseEndTimeout = time.Minute
+
+// ReleaseAndPublish releases an application package, waits for the release
+// operation, resolves the released application, labels and publishes it if
+// requested. When a step fails after the release was launched, the released
+// application is deleted unless opts.KeepOnFailure: a release operation still
+// running, after a timeout or a cancellation, is cancelled and awaited first.
+// A step fails if its operation does not succeed on every system. The report
+// is returned even with an error. opts may be nil.
+func (av *AirVantage) ReleaseAndPublish(pkg *avapp.Package, opts *ReleaseOptions) (*ReleaseReport, error) {
+	return av.ReleaseAndPublishContext(context.Background(), pkg, opts)
+}
+
+// ReleaseAndPublishContext is like ReleaseAndPublish but uses ctx for the API calls.
+func (av *AirVantage) ReleaseAndPublishContext(ctx context.Context, pkg *avapp.Package, opts *ReleaseOptions) (*ReleaseReport, error) {
+	var o ReleaseOptions
+	if opts != nil {
+		o = *opts
+	}
+	m := pkg.Manifest
+	report := &ReleaseReport{}
+
+	released := false
+	fail := func(step string, err error) (*ReleaseReport, error) {
+		report.FailedStep = step
+		err = fmt.Errorf("%s of %s %s: %w", step, m.Type, m.Revision, err)
+		if released && !o.KeepOnFailure {
+			av.rollbackRelease(ctx, report, m, o.Await)
+		}
+		return report, err
+	}
+
+	zip, err := pkg.Zip()
+	if err != nil {
+		return fail(StepPackage, err)
+	}
+
+	// an application released before must not be deleted by the rollback
+	if app, err := av.findRelease(ctx, m.Type, m.Revision); err != nil {
+		return fail(StepRelease, err)
+	} else if app != nil {
+		report.Application = app
+		return fail(StepRelease, fmt.Errorf("already released as %s", app.UID))
+	}
+
+	if report.ReleaseOperation, err = av.ReleaseApplicationWithOptionsContext(ctx, bytes.NewReader(zip), o.Operation); err != nil {
+		return fail(StepRelease, err)
+	}
+	released = true
+	if err = av.awaitStep(ctx, report.ReleaseOperation, o.Await); err != nil {
+		return fail(StepRelease, err)
+	}
+
+	if report.Application, err = av.findRelease(ctx, m.Type, m.Revision); err != nil {
+		return fail(StepResolve, err)
+	}
+	if report.Application == nil {
+		return fail(StepResolve, errors.New("application not found after its release"))
+	}
+
+	if len(o.Labels) > 0 {
+		app, err := av.AddApplicationLabelsContext(ctx, report.Application.UID, o.Labels...)
+		if err != nil {
+			return fail(StepLabel, err)
+		}
+		report.Application = app
+	}
+
+	if o.Publish {
+		if report.PublishOperation, err = av.PublishApplicationContext(ctx, report.Application.UID, o.Operation); err != nil {
+			return fail(StepPublish, err)
+		}
+		if err = av.awaitStep(ctx, report.PublishOperation, o.Await); err != nil {
+			return fail(StepPublish, err)
+		}
+		app, err := av.GetApplicationContext(ctx, report.Application.UID)
+		if err != nil {
+			return fail(StepPublish, err)
+		}
+		report.Application = app
+	}
+
+	return report, nil
+}
+
+// awaitStep waits for an operation of ReleaseAndPublish, failing if it did
+// not succeed on every system.
+func (av *AirVantage) awaitStep(ctx context.Context, opUID string, opts *AwaitOptions) error {
+	res, err := av.AwaitOperationResultContext(ctx, opUID, opts)
+	switch {
+	case err != nil:
+		return err
+	case res.State != OperationFinished:
+		return fmt.Errorf("operation %s %s", opUID, res.State)
+	case !res.AllSucceeded:
+		c := res.Counters
+		return fmt.Errorf("operation %s finished with %d failed and %d cancelled", opUID, c.Failure, c.Cancelled+c.BeingCancelled)
+	}
+	return nil
+}
+
+// findRelease returns the application with the given type and revision, or nil.
+func (av *AirVantage) findRelease(ctx context.Context, appType, revision string) (*Application, error) {
+	for app, err := range av.AllApplicationsByQuery(ctx, ApplicationQuery{Type: appType, Revision: revision}) {
+		if err != nil {
+			return nil, err
+		}
+		return &app, nil
+	}
+	return nil, nil
+}
+
+// rollbackRelease deletes the application released by a failed
+// ReleaseAndPublish, even if ctx is cancelled.
+func (av *AirVantage) rollbackRelease(ctx context.Context, report *ReleaseReport, m avapp.Manifest, opts *AwaitOptions) {
+	ctx = context.WithoutCancel(ctx)
+
+	app := report.Application
+	if app == nil {
+		// a running release would create the application after the lookup
+		state, err := av.endRelease(ctx, report.ReleaseOperation, opts)
+		if err != nil {
+			report.RollbackError = err
+			return
+		}
+
+		// a failed release may have left the application half-released
+		if app, report.RollbackError = av.findRelease(ctx, m.Type, m.Revision); report.RollbackError != nil {
+			return
+		}
+		if app == nil {
+			if state == OperationFinished {
+				report.RollbackError = errors.New("released application not found")
+			}
+			return
+		}
+	}
+
+	av.logger().Debug("Deleting the released application", "uid", app.UID, "step", report.FailedStep)
+	if report.RollbackError = av.DeleteApplicationContext(ctx, app.UID); report.RollbackError == nil {
+		report.RolledBack = true
+	}
+}
+
+// endRelease cancels a release operation still running and waits for its
+// end, returning its final state.
+func (av *AirVantage) endRelease(ctx context.Context, opUID string, opts *AwaitOptions) (string, error) {
+	op, err := av.GetOperationContext(ctx, opUID)
+	if err != nil {
+		return "", err
+	}
+	if op.IsTerminal() {
+		return op.State, nil
+	}
+
+	av.logger().Debug("Cancelling the release operation", "uid", opUID)
+	if _, err = av.CancelOperationContext(ctx, opUID); err != nil {
+		av.logger().Debug("Unable to cancel the release operation", "uid", opUID, "error", err)
+	}
+
+	o := opts.withDefaults()
+	if o.Timeout <= 0 {
+		o.Timeout = releaseEndTimeout
+	}
+	o.OnProgress = nil
+	res, err := av.AwaitOperationResultContext(ctx, opUID, &o)
+	if err != nil {
+		if res != nil {
+			return "", fmt.Errorf("release operation %s still %s, the application may be released later: %w", opUID, res.State, err)
+		}
+		return "", err
+	}
+	return res.State, nil
+}
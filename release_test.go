@@ -0,0 +1,133 @@
+package airvantage_test
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+	"github.com/AirVantage/airvantage-api-go/avapp"
+	"github.com/AirVantage/airvantage-api-go/avtest"
+)
+
+func testPackage() *avapp.Package {
+	pkg := avapp.NewPackage(avapp.Manifest{Type: "com.example.app", Revision: "1.0.0"})
+	pkg.AddBinary("app.tar", []byte("binary"))
+	return pkg
+}
+
+func TestReleaseAndPublish(t *testing.T) {
+	srv, av := newServer(t)
+
+	report, err := av.ReleaseAndPublish(testPackage(), &airvantage.ReleaseOptions{
+		Publish: true,
+		Labels:  []string{"beta"},
+		Await:   &airvantage.AwaitOptions{Interval: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.ReleaseOperation == "" || report.PublishOperation == "" {
+		t.Errorf("missing operations: %+v", report)
+	}
+	app := report.Application
+	if app.Type != "com.example.app" || app.State != airvantage.ApplicationPublished || !slices.Equal(app.Labels, []string{"beta"}) {
+		t.Errorf("invalid application: %+v", app)
+	}
+
+	// the same revision cannot be released twice, and is not rolled back
+	if report, err = av.ReleaseAndPublish(testPackage(), nil); err == nil {
+		t.Fatal("a second release should fail")
+	}
+	if report.FailedStep != airvantage.StepRelease || report.RolledBack || len(srv.Applications()) != 1 {
+		t.Errorf("invalid report of the second release: %+v", report)
+	}
+}
+
+func TestReleaseAndPublishRollback(t *testing.T) {
+	srv, av := newServer(t)
+	srv.InjectFault(avtest.Fault{Path: "/api/v1/operations/applications/publish", Status: http.StatusBadRequest})
+
+	report, err := av.ReleaseAndPublish(testPackage(), &airvantage.ReleaseOptions{
+		Publish: true,
+		Await:   &airvantage.AwaitOptions{Interval: time.Millisecond},
+	})
+	if err == nil {
+		t.Fatal("the publication should fail")
+	}
+	if report.FailedStep != airvantage.StepPublish || !report.RolledBack || report.RollbackError != nil {
+		t.Errorf("invalid report: %+v", report)
+	}
+	if apps := srv.Applications(); len(apps) != 0 {
+		t.Errorf("the application was not deleted: %+v", apps)
+	}
+}
+
+func TestReleaseAndPublishTimeout(t *testing.T) {
+	srv, av := newServer(t)
+	srv.FinishOperationsAfter(-1)
+	await := &airvantage.AwaitOptions{Interval: time.Millisecond, Timeout: 20 * time.Millisecond}
+
+	// the running release is cancelled before deleting the application
+	report, err := av.ReleaseAndPublish(testPackage(), &airvantage.ReleaseOptions{Await: await})
+	if err == nil {
+		t.Fatal("the release should time out")
+	}
+	if report.FailedStep != airvantage.StepRelease || !report.RolledBack || report.RollbackError != nil {
+		t.Errorf("invalid report: %+v", report)
+	}
+	if op, _ := srv.Operation(report.ReleaseOperation); op.State != airvantage.OperationCancelled {
+		t.Errorf("the release was not cancelled: %+v", op)
+	}
+	if apps := srv.Applications(); len(apps) != 0 {
+		t.Errorf("the application was not deleted: %+v", apps)
+	}
+
+	// a release which cannot be cancelled is not rolled back
+	pkg := testPackage()
+	pkg.Manifest.Revision = "2.0.0"
+	report, err = av.ReleaseAndPublish(pkg, &airvantage.ReleaseOptions{Await: &airvantage.AwaitOptions{
+		Interval: time.Millisecond,
+		Timeout:  20 * time.Millisecond,
+		OnProgress: func(airvantage.Operation, airvantage.OperationCounters) {
+			srv.InjectFault(avtest.Fault{Method: http.MethodPost, Path: "/api/v1/operations/", Status: http.StatusBadRequest})
+		},
+	}})
+	if err == nil {
+		t.Fatal("the release should time out")
+	}
+	if report.RolledBack || report.RollbackError == nil || len(srv.Applications()) != 1 {
+		t.Errorf("expected a rollback error, got: %+v", report)
+	}
+}
+
+func TestReleaseAndPublishPartialFailure(t *testing.T) {
+	srv, av := newServer(t)
+	srv.FinishOperationsAfter(-1)
+
+	report, err := av.ReleaseAndPublish(testPackage(), &airvantage.ReleaseOptions{Await: &airvantage.AwaitOptions{
+		Interval: time.Millisecond,
+		OnProgress: func(op airvantage.Operation, _ airvantage.OperationCounters) {
+			srv.SetOperation(op.UID, airvantage.OperationFinished, airvantage.OperationCounters{Success: 1, Failure: 1})
+		},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "1 failed") {
+		t.Fatalf("expected a partial failure, got: %v", err)
+	}
+	if report.FailedStep != airvantage.StepRelease || !report.RolledBack {
+		t.Errorf("invalid report: %+v", report)
+	}
+}
+
+func TestReleaseAndPublishInvalidPackage(t *testing.T) {
+	_, av := newServer(t)
+
+	pkg := testPackage()
+	pkg.Manifest.Revision = ""
+	report, err := av.ReleaseAndPublish(pkg, nil)
+	if err == nil || report.FailedStep != airvantage.StepPackage || report.ReleaseOperation != "" {
+		t.Errorf("expected a package failure, got %v and %+v", err, report)
+	}
+}
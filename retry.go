@@ -0,0 +1,157 @@
+package airvantage
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A RetryPolicy controls how the requests failing with a transient error
+// (429, 502, 503, 504 or a network error) are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	// A value lower than 2 disables the retries.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry. It doubles at each
+	// attempt, with a random jitter.
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts, including the delay
+	// requested by a Retry-After header.
+	MaxBackoff time.Duration
+	// RetryOperations enables the retries of the requests launching an
+	// operation. They are not idempotent: a retry may launch it twice.
+	RetryOperations bool
+}
+
+// DefaultRetryPolicy is the policy of the clients created without WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  500 * time.Millisecond,
+	MaxBackoff:  30 * time.Second,
+}
+
+// WithRetryPolicy sets the retry policy of the client.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *clientOptions) {
+		o.retry = &policy
+	}
+}
+
+// retryable tells if the request can be sent again after the given attempt.
+func (p RetryPolicy) retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if err == nil {
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests:
+			// the request has not been processed
+			return true
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		default:
+			return false
+		}
+	}
+
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		return p.RetryOperations && strings.Contains(req.URL.Path, "/operations/")
+	}
+	return false
+}
+
+// backoff returns the delay before the next attempt.
+func (p RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+				delay = p.MaxBackoff
+			}
+			return delay
+		}
+	}
+
+	delay := p.MinBackoff << (attempt - 1)
+	if delay <= 0 || (p.MaxBackoff > 0 && delay > p.MaxBackoff) {
+		delay = p.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// jitter between half and the full delay
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// parseRetryAfter reads a Retry-After header, in seconds or as an HTTP date.
+func parseRetryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0), true
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// doWithRetry sends the request, retrying it according to the policy of the client.
+func (av *AirVantage) doWithRetry(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		release, err := av.acquire(req.Context())
+		if err != nil {
+			return nil, err
+		}
+		resp, err := av.client.Do(req)
+		if err != nil {
+			release()
+		} else {
+			// a streamed body keeps the slot until it is closed
+			resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+		}
+
+		if attempt >= av.retry.MaxAttempts || !av.retry.retryable(req, resp, err) {
+			return resp, err
+		}
+
+		delay := av.retry.backoff(attempt, resp)
+
+		attrs := []any{"method", req.Method, "url", maskUrlParams(req.URL.String(), secretUrlParams),
+			"attempt", attempt + 1, "delay", delay}
+		if err != nil {
+			attrs = append(attrs, "error", err)
+		} else {
+			attrs = append(attrs, "status", resp.StatusCode)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		av.logger().Debug("Retrying request", attrs...)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		retry := req.Clone(req.Context())
+		if req.GetBody != nil {
+			if retry.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		req = retry
+	}
+}
@@ -0,0 +1,98 @@
+package airvantage
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *AirVantage {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/oauth/token" {
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"access_token":"token","token_type":"bearer","expires_in":3600}`)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	av, err := NewClient(srv.URL, "id", "secret", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return av
+}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	av := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		switch calls {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			io.WriteString(w, `{"uid":"uid","state":"FINISHED"}`)
+		}
+	}, WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}))
+
+	op, err := av.GetOperation("uid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.State != "FINISHED" || calls != 3 {
+		t.Fatalf("expected a finished operation after 3 calls, got: %v after %d calls", op.State, calls)
+	}
+}
+
+func TestRetryOperations(t *testing.T) {
+	for _, retryOps := range []bool{false, true} {
+		calls := 0
+		av := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			if body, _ := io.ReadAll(r.Body); len(body) == 0 {
+				t.Error("empty body on retry")
+			}
+			io.WriteString(w, `{"operation":"op"}`)
+		}, WithRetryPolicy(RetryPolicy{MaxAttempts: 2, MinBackoff: time.Millisecond, RetryOperations: retryOps}))
+
+		_, err := av.Reboot("", "uid")
+		if retryOps && (err != nil || calls != 2) {
+			t.Fatalf("expected a retried operation launch, got: %v after %d calls", err, calls)
+		}
+		if !retryOps && (err == nil || calls != 1) {
+			t.Fatalf("expected no retry, got: %v after %d calls", err, calls)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d, ok := parseRetryAfter("120"); !ok || d != 2*time.Minute {
+		t.Fatalf("expected: %v, got: %v", 2*time.Minute, d)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if d, ok := parseRetryAfter(date); !ok || d < 59*time.Minute {
+		t.Fatalf("expected about an hour, got: %v", d)
+	}
+	if _, ok := parseRetryAfter("soon"); ok {
+		t.Fatal("expected an invalid header")
+	}
+
+	policy := RetryPolicy{MinBackoff: time.Second, MaxBackoff: 3 * time.Second}
+	for attempt := 1; attempt < 5; attempt++ {
+		if d := policy.backoff(attempt, nil); d > 3*time.Second || d < 500*time.Millisecond {
+			t.Fatalf("invalid backoff for attempt %d: %v", attempt, d)
+		}
+	}
+}
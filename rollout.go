@@ -0,0 +1,286 @@
+package airvantage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Errors returned when a rollout stops on a failed wave.
+var (
+	ErrRolloutPaused  = errors.New("rollout paused")
+	ErrRolloutAborted = errors.New("rollout aborted")
+)
+
+// Rollout statuses.
+const (
+	RolloutRunning   = "RUNNING"
+	RolloutPaused    = "PAUSED"
+	RolloutAborted   = "ABORTED"
+	RolloutCompleted = "COMPLETED"
+)
+
+// RolloutWave is a step of a rollout.
+type RolloutWave struct {
+	// Percent of the targeted systems reached at the end of the wave, the
+	// waves before included: 1, 10 then 100 for a 1% canary wave followed
+	// by waves of 9% and 90% of the systems.
+	Percent float64 `json:"percent"`
+	// MaxFailureRate is the part of the systems of the wave, from 0 to 1,
+	// allowed to fail or be cancelled before the rollout stops.
+	MaxFailureRate float64 `json:"maxFailureRate"`
+	// Soak is the delay after the wave before launching the next one.
+	Soak time.Duration `json:"soak,omitempty"`
+}
+
+// RolloutPlan describes the installation of an application in waves.
+type RolloutPlan struct {
+	// Application is the UID of the installed application.
+	Application string `json:"application"`
+	// Targets are the systems of the rollout. They are resolved when the
+	// rollout starts, a saved query cannot be used.
+	Targets SystemSelection `json:"targets"`
+	Waves   []RolloutWave   `json:"waves"`
+	// Abort stops the rollout for good when a wave fails, instead of pausing it.
+	Abort bool `json:"abort,omitempty"`
+	// Operation configures the install operations.
+	Operation *OperationOptions `json:"operation,omitempty"`
+}
+
+// Validate checks the plan, reporting all its errors.
+func (p *RolloutPlan) Validate() error {
+	var errs []error
+
+	if p.Application == "" {
+		errs = append(errs, errors.New("no application to roll out"))
+	}
+	if err := p.Targets.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("targets: %w", err))
+	} else if p.Targets.Selection != "" {
+		errs = append(errs, errors.New("targets: a saved query cannot be split into waves"))
+	}
+	if len(p.Waves) == 0 {
+		errs = append(errs, errors.New("rollout without wave"))
+	}
+	prev := 0.0
+	for i, wave := range p.Waves {
+		if wave.Percent <= prev || wave.Percent > 100 {
+			errs = append(errs, fmt.Errorf("wave %d: percent %v not in (%v, 100]", i, wave.Percent, prev))
+		}
+		if wave.MaxFailureRate < 0 || wave.MaxFailureRate > 1 {
+			errs = append(errs, fmt.Errorf("wave %d: failure rate %v not in [0, 1]", i, wave.MaxFailureRate))
+		}
+		prev = wave.Percent
+	}
+	if prev != 100 {
+		errs = append(errs, errors.New("the last wave does not reach 100%"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// RolloutState is the progress of a rollout, saved as JSON to resume it.
+type RolloutState struct {
+	Plan   RolloutPlan `json:"plan"`
+	Status string      `json:"status"`
+	// Reason explains why the rollout is paused or aborted.
+	Reason string `json:"reason,omitempty"`
+	// Systems are the UIDs of the targeted systems, in the order of the waves.
+	Systems []string    `json:"systems"`
+	Waves   []WaveState `json:"waves"`
+}
+
+// WaveState is the progress of a wave.
+type WaveState struct {
+	// From and To delimit the systems of the wave in RolloutState.Systems.
+	From int `json:"from"`
+	To   int `json:"to"`
+	// Operation is the UID of the install operation, empty until launched.
+	Operation string            `json:"operation,omitempty"`
+	State     string            `json:"state,omitempty"`
+	Counters  OperationCounters `json:"counters"`
+	// Done tells if the rollout went past the wave.
+	Done bool `json:"done,omitempty"`
+}
+
+// RolloutOptions configures the run of a rollout.
+type RolloutOptions struct {
+	// Save persists the state after each change, to resume the rollout if the
+	// process is interrupted. An error of Save stops the rollout.
+	Save func(state *RolloutState) error
+	// Await configures the wait of the install operations.
+	Await *AwaitOptions
+}
+
+// StartRollout resolves the targets of the plan, splits them into waves and
+// runs the rollout. Each wave is launched once the previous one finished
+// with fewer failures than its threshold, and its soak delay elapsed. A wave
+// over its threshold pauses or aborts the rollout, returning
+// ErrRolloutPaused or ErrRolloutAborted. The state is returned even with an
+// error. opts may be nil.
+func (av *AirVantage) StartRollout(plan RolloutPlan, opts *RolloutOptions) (*RolloutState, error) {
+	return av.StartRolloutContext(context.Background(), plan, opts)
+}
+
+// StartRolloutContext is like StartRollout but uses ctx for the API calls and the soak delays.
+func (av *AirVantage) StartRolloutContext(ctx context.Context, plan RolloutPlan, opts *RolloutOptions) (*RolloutState, error) {
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+
+	systems, err := av.resolveTargets(ctx, plan.Targets)
+	if err != nil {
+		return nil, fmt.Errorf("targets: %w", err)
+	}
+
+	state := &RolloutState{Plan: plan, Status: RolloutRunning, Systems: systems}
+	from := 0
+	for _, wave := range plan.Waves {
+		to := min(int(math.Ceil(float64(len(systems))*wave.Percent/100)), len(systems))
+		state.Waves = append(state.Waves, WaveState{From: from, To: to})
+		from = to
+	}
+	return av.ResumeRolloutContext(ctx, state, opts)
+}
+
+// ResumeRollout continues a rollout from its saved state, waiting for the
+// operation of the current wave if it was launched. A paused rollout resumes
+// with the wave following the failed one: its failures are accepted. A
+// rollout interrupted between the launch of a wave and the save of the state
+// launches the wave again. opts may be nil.
+func (av *AirVantage) ResumeRollout(state *RolloutState, opts *RolloutOptions) (*RolloutState, error) {
+	return av.ResumeRolloutContext(context.Background(), state, opts)
+}
+
+// ResumeRolloutContext is like ResumeRollout but uses ctx for the API calls and the soak delays.
+func (av *AirVantage) ResumeRolloutContext(ctx context.Context, state *RolloutState, opts *RolloutOptions) (*RolloutState, error) {
+	var o RolloutOptions
+	if opts != nil {
+		o = *opts
+	}
+	save := func() error {
+		if o.Save == nil {
+			return nil
+		}
+		if err := o.Save(state); err != nil {
+			return fmt.Errorf("save the rollout state: %w", err)
+		}
+		return nil
+	}
+
+	switch state.Status {
+	case RolloutAborted:
+		return state, fmt.Errorf("%w: %s", ErrRolloutAborted, state.Reason)
+	case RolloutCompleted:
+		return state, nil
+	case RolloutPaused:
+		for i := range state.Waves {
+			if !state.Waves[i].Done {
+				state.Waves[i].Done = true
+				break
+			}
+		}
+		state.Status, state.Reason = RolloutRunning, ""
+		if err := save(); err != nil {
+			return state, err
+		}
+	}
+
+	for i := range state.Waves {
+		wave, plan := &state.Waves[i], state.Plan.Waves[i]
+		if wave.Done {
+			continue
+		}
+
+		if wave.Operation == "" && wave.To > wave.From {
+			opUID, err := av.InstallApplicationBySelectionContext(ctx, state.Plan.Application,
+				SelectSystems(state.Systems[wave.From:wave.To]...), state.Plan.Operation)
+			if err != nil {
+				return state, fmt.Errorf("wave %d: %w", i, err)
+			}
+			wave.Operation = opUID
+			if err = save(); err != nil {
+				return state, err
+			}
+			av.logger().Debug("Rollout wave launched", "wave", i, "operation", opUID, "systems", wave.To-wave.From)
+		}
+
+		if wave.Operation != "" {
+			res, err := av.AwaitOperationResultContext(ctx, wave.Operation, o.Await)
+			if err != nil {
+				return state, fmt.Errorf("wave %d: %w", i, err)
+			}
+			wave.State, wave.Counters = res.State, res.Counters
+
+			failed := res.Counters.Failure + res.Counters.Cancelled
+			rate := float64(failed) / float64(wave.To-wave.From)
+			switch {
+			case res.State != OperationFinished:
+				state.Reason = fmt.Sprintf("wave %d: operation %s %s", i, wave.Operation, res.State)
+			case rate > plan.MaxFailureRate:
+				state.Reason = fmt.Sprintf("wave %d: %d of %d systems failed", i, failed, wave.To-wave.From)
+			}
+			if state.Reason != "" {
+				return state, av.stopRollout(state, save)
+			}
+		}
+
+		wave.Done = true
+		if err := save(); err != nil {
+			return state, err
+		}
+
+		if plan.Soak > 0 && i < len(state.Waves)-1 {
+			timer := time.NewTimer(plan.Soak)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return state, ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+
+	state.Status = RolloutCompleted
+	return state, save()
+}
+
+// stopRollout pauses or aborts a rollout on a failed wave.
+func (av *AirVantage) stopRollout(state *RolloutState, save func() error) error {
+	state.Status = RolloutPaused
+	stopped := ErrRolloutPaused
+	if state.Plan.Abort {
+		state.Status, stopped = RolloutAborted, ErrRolloutAborted
+	}
+	av.logger().Warn("Rollout stopped", "status", state.Status, "reason", state.Reason)
+
+	if err := save(); err != nil {
+		return errors.Join(fmt.Errorf("%w: %s", stopped, state.Reason), err)
+	}
+	return fmt.Errorf("%w: %s", stopped, state.Reason)
+}
+
+// resolveTargets returns the UIDs of the selected systems.
+func (av *AirVantage) resolveTargets(ctx context.Context, sel SystemSelection) ([]string, error) {
+	if len(sel.UIDs) > 0 {
+		return sel.UIDs, nil
+	}
+
+	q := NewSystemQuery().Fields("uid")
+	if len(sel.Labels) > 0 {
+		q.AnyLabels(sel.Labels...)
+	}
+	var uids []string
+	for sys, err := range av.AllSystemsByQuery(ctx, q) {
+		if err != nil {
+			return nil, err
+		}
+		uids = append(uids, sys.UID)
+	}
+	if len(uids) == 0 {
+		return nil, errors.New("no system selected")
+	}
+	return uids, nil
+}
@@ -0,0 +1,102 @@
+package airvantage_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+func TestRollout(t *testing.T) {
+	srv, av := newServer(t)
+	ctx := context.Background()
+
+	var uids []string
+	for range 10 {
+		uids = append(uids, srv.AddSystem(airvantage.System{Labels: []string{"fleet"}}).UID)
+	}
+	// a system of the second wave fails
+	srv.FailSystems("install.failed", uids[3])
+
+	plan := airvantage.RolloutPlan{
+		Application: "app",
+		Targets:     airvantage.SelectLabels("fleet"),
+		Waves:       []airvantage.RolloutWave{{Percent: 10}, {Percent: 50}, {Percent: 100, MaxFailureRate: 0.5}},
+	}
+	var saved []byte
+	opts := &airvantage.RolloutOptions{
+		Save: func(state *airvantage.RolloutState) (err error) {
+			saved, err = json.Marshal(state)
+			return err
+		},
+		Await: &airvantage.AwaitOptions{Interval: time.Millisecond},
+	}
+
+	state, err := av.StartRolloutContext(ctx, plan, opts)
+	if !errors.Is(err, airvantage.ErrRolloutPaused) {
+		t.Fatalf("expected a paused rollout, got %v", err)
+	}
+	if state.Status != airvantage.RolloutPaused || !state.Waves[0].Done || state.Waves[1].Done || state.Waves[1].Counters.Failure != 1 {
+		t.Errorf("invalid paused state: %+v", state)
+	}
+
+	// resume from the saved state, accepting the failure
+	resumed := &airvantage.RolloutState{}
+	if err = json.Unmarshal(saved, resumed); err != nil {
+		t.Fatal(err)
+	}
+	if state, err = av.ResumeRolloutContext(ctx, resumed, opts); err != nil {
+		t.Fatal(err)
+	}
+	if state.Status != airvantage.RolloutCompleted {
+		t.Errorf("invalid status %s", state.Status)
+	}
+
+	ops := srv.Operations()
+	sizes := make([]int, len(ops))
+	for i, op := range ops {
+		sizes[i] = len(op.Systems)
+	}
+	if len(sizes) != 3 || sizes[0] != 1 || sizes[1] != 4 || sizes[2] != 5 {
+		t.Errorf("invalid waves sizes %v", sizes)
+	}
+}
+
+func TestRolloutAbort(t *testing.T) {
+	srv, av := newServer(t)
+
+	srv.FailSystems("install.failed", "s1")
+	plan := airvantage.RolloutPlan{
+		Application: "app",
+		Targets:     airvantage.SelectSystems("s1", "s2", "s3"),
+		Waves:       []airvantage.RolloutWave{{Percent: 1}, {Percent: 100}},
+		Abort:       true,
+	}
+	state, err := av.StartRollout(plan, &airvantage.RolloutOptions{Await: &airvantage.AwaitOptions{Interval: time.Millisecond}})
+	if !errors.Is(err, airvantage.ErrRolloutAborted) || state.Status != airvantage.RolloutAborted {
+		t.Fatalf("expected an aborted rollout, got %v", err)
+	}
+	if _, err = av.ResumeRollout(state, nil); !errors.Is(err, airvantage.ErrRolloutAborted) {
+		t.Errorf("an aborted rollout should not resume, got %v", err)
+	}
+	if ops := srv.Operations(); len(ops) != 1 {
+		t.Errorf("expected only the canary wave, got %d operations", len(ops))
+	}
+}
+
+func TestRolloutPlanValidate(t *testing.T) {
+	plan := airvantage.RolloutPlan{
+		Targets: airvantage.SelectSavedQuery("q"),
+		Waves:   []airvantage.RolloutWave{{Percent: 50}, {Percent: 20, MaxFailureRate: 2}},
+	}
+	err := plan.Validate()
+	for _, expected := range []string{"no application", "saved query", "wave 1: percent", "wave 1: failure rate", "100%"} {
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected an error about '%s', got %v", expected, err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package airvantage
+
+import "errors"
+
+// SystemSelection selects the systems targeted by an operation. Exactly one
+// of its fields must be set, use the Select* functions to build it.
+type SystemSelection struct {
+	// UIDs of the systems.
+	UIDs []string `json:"uids,omitempty"`
+	// Labels selects the systems having at least one of the labels.
+	Labels []string `json:"labels,omitempty"`
+	// Selection is the UID of a saved systems query.
+	Selection string `json:"selection,omitempty"`
+	// All selects all the systems of the company.
+	All bool `json:"all,omitempty"`
+}
+
+// SelectSystems selects the systems with the given UIDs.
+func SelectSystems(uids ...string) SystemSelection {
+	return SystemSelection{UIDs: uids}
+}
+
+// SelectLabels selects the systems having at least one of the labels.
+func SelectLabels(labels ...string) SystemSelection {
+	return SystemSelection{Labels: labels}
+}
+
+// SelectSavedQuery selects the systems matching a saved query.
+func SelectSavedQuery(uid string) SystemSelection {
+	return SystemSelection{Selection: uid}
+}
+
+// SelectCompany selects all the systems of the company.
+func SelectCompany() SystemSelection {
+	return SystemSelection{All: true}
+}
+
+// Validate checks that exactly one way of selecting the systems is used.
+func (s SystemSelection) Validate() error {
+	n := 0
+	for _, set := range []bool{len(s.UIDs) > 0, len(s.Labels) > 0, s.Selection != "", s.All} {
+		if set {
+			n++
+		}
+	}
+	switch n {
+	case 0:
+		return errors.New("empty systems selection")
+	case 1:
+		return nil
+	}
+	return errors.New("systems selected in several ways")
+}
@@ -0,0 +1,54 @@
+package airvantage_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+func TestSystemSelection(t *testing.T) {
+	srv, av := newServer(t)
+
+	sys1 := srv.AddSystem(airvantage.System{Name: "sys1", Labels: []string{"fleet"}})
+	sys2 := srv.AddSystem(airvantage.System{Name: "sys2", Labels: []string{"fleet", "v2"}})
+	sys3 := srv.AddSystem(airvantage.System{Name: "sys3"})
+
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		sel      airvantage.SystemSelection
+		expected []string
+	}{
+		{"uids", airvantage.SelectSystems(sys1.UID, sys3.UID), []string{sys1.UID, sys3.UID}},
+		{"labels", airvantage.SelectLabels("v2"), []string{sys2.UID}},
+		{"saved query", airvantage.SelectSavedQuery(srv.SaveSelection(sys3.UID)), []string{sys3.UID}},
+		{"company", airvantage.SelectCompany(), []string{sys1.UID, sys2.UID, sys3.UID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opUID, err := av.RebootBySelectionContext(ctx, "", tt.sel, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			op, ok := srv.Operation(opUID)
+			if !ok || !slices.Equal(op.Systems, tt.expected) {
+				t.Fatalf("expected systems %v, got: %+v", tt.expected, op)
+			}
+		})
+	}
+
+	invalid := []airvantage.SystemSelection{
+		{},
+		{UIDs: []string{sys1.UID}, All: true},
+	}
+	for _, sel := range invalid {
+		if _, err := av.SendCommandBySelectionContext(ctx, "cmd", nil, "", sel, nil); err == nil {
+			t.Errorf("expected an error for the selection %+v", sel)
+		}
+	}
+	if n := len(srv.Operations()); n != len(tests) {
+		t.Fatalf("expected %d operations, got: %d", len(tests), n)
+	}
+}
@@ -2,10 +2,11 @@ package airvantage
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log/slog"
+	"iter"
 	"mime/multipart"
 	"net/http"
 	"net/textproto"
@@ -48,12 +49,7 @@ type System struct {
 }
 
 // A Datapoint retrieved from a System.
-//
-//lint:ignore U1000 used in DataAggregate
-type Datapoint struct {
-	ts AVTime
-	v  any
-}
+type Datapoint = TsValueV2
 
 type Info struct {
 	Uid         string `json:"uid"`
@@ -78,56 +74,50 @@ type DataAggregate map[string]map[string][]Datapoint
 
 // ApplyTemplateByUID applies the settings of a given template on a list of systems.
 func (av *AirVantage) ApplyTemplateByUID(templateName string, systemUIDs []string) (*Operation, error) {
+	return av.ApplyTemplateByUIDContext(context.Background(), templateName, systemUIDs)
+}
 
-	reqMsg := struct {
-		Systems struct {
-			UIDs []string `json:"uids"`
-		} `json:"systems"`
-		Template string `json:"templateName"`
-	}{Template: templateName}
-	reqMsg.Systems.UIDs = systemUIDs
-
-	js, err := json.Marshal(&reqMsg)
-	if err != nil {
-		return nil, err
-	}
-
-	url := av.URL("/operations/systems/settings")
-	slog.Debug("HTTP POST", "url", url, "json", string(js))
+// ApplyTemplateByUIDContext is like ApplyTemplateByUID but uses ctx for the API calls.
+func (av *AirVantage) ApplyTemplateByUIDContext(ctx context.Context, templateName string, systemUIDs []string) (*Operation, error) {
+	return av.ApplyTemplateBySelectionContext(ctx, templateName, SelectSystems(systemUIDs...), nil)
+}
 
-	resp, err := av.client.Post(url, "application/json", bytes.NewReader(js))
-	if err != nil {
-		return nil, err
-	}
+// ApplyTemplateByLabels applies a template on all the systems with given labels.
+func (av *AirVantage) ApplyTemplateByLabels(templateName string, labels []string) (*Operation, error) {
+	return av.ApplyTemplateByLabelsContext(context.Background(), templateName, labels)
+}
 
-	op := &Operation{}
-	if err = av.parseResponse(resp, op); err != nil {
-		return nil, err
-	}
+// ApplyTemplateByLabelsContext is like ApplyTemplateByLabels but uses ctx for the API calls.
+func (av *AirVantage) ApplyTemplateByLabelsContext(ctx context.Context, templateName string, labels []string) (*Operation, error) {
+	return av.ApplyTemplateBySelectionContext(ctx, templateName, SelectLabels(labels...), nil)
+}
 
-	return op, nil
+// ApplyTemplateBySelection applies the settings of a given template on the selected systems.
+// opts may be nil.
+func (av *AirVantage) ApplyTemplateBySelection(templateName string, sel SystemSelection, opts *OperationOptions) (*Operation, error) {
+	return av.ApplyTemplateBySelectionContext(context.Background(), templateName, sel, opts)
 }
 
-// ApplyTemplateByLabels applies a template on all the systems with given labels.
-func (av *AirVantage) ApplyTemplateByLabels(templateName string, labels []string) (*Operation, error) {
+// ApplyTemplateBySelectionContext is like ApplyTemplateBySelection but uses ctx for the API calls.
+func (av *AirVantage) ApplyTemplateBySelectionContext(ctx context.Context, templateName string, sel SystemSelection, opts *OperationOptions) (*Operation, error) {
+	if err := sel.Validate(); err != nil {
+		return nil, err
+	}
 
 	reqMsg := struct {
-		Systems struct {
-			Labels []string `json:"labels"`
-		} `json:"systems"`
-		Template string `json:"templateName"`
-	}{Template: templateName}
-	reqMsg.Systems.Labels = labels
-
-	js, err := json.Marshal(&reqMsg)
+		Systems  SystemSelection `json:"systems"`
+		Template string          `json:"templateName"`
+	}{sel, templateName}
+
+	js, err := marshalLaunch(&reqMsg, opts)
 	if err != nil {
 		return nil, err
 	}
 
 	url := av.URL("/operations/systems/settings")
-	slog.Debug("HTTP POST", "url", url, "json", string(js))
+	av.logger().Debug("HTTP POST", "url", url, "json", string(js))
 
-	resp, err := av.client.Post(url, "application/json", bytes.NewReader(js))
+	resp, err := av.post(ctx, url, "application/json", bytes.NewReader(js))
 	if err != nil {
 		return nil, err
 	}
@@ -144,15 +134,20 @@ func (av *AirVantage) ApplyTemplateByLabels(templateName string, labels []string
 // with updated information.
 // Required fields in System: name, gateway
 func (av *AirVantage) CreateSystem(system *System) (*System, error) {
+	return av.CreateSystemContext(context.Background(), system)
+}
+
+// CreateSystemContext is like CreateSystem but uses ctx for the API calls.
+func (av *AirVantage) CreateSystemContext(ctx context.Context, system *System) (*System, error) {
 
 	url := av.URL("systems")
 	js, err := json.Marshal(system)
 	if err != nil {
 		return nil, err
 	}
-	slog.Debug("HTTP POST", "url", url, "json", string(js))
+	av.logger().Debug("HTTP POST", "url", url, "json", string(js))
 
-	resp, err := av.client.Post(url, "application/json", bytes.NewReader(js))
+	resp, err := av.post(ctx, url, "application/json", bytes.NewReader(js))
 	if err != nil {
 		return nil, err
 	}
@@ -167,49 +162,50 @@ func (av *AirVantage) CreateSystem(system *System) (*System, error) {
 
 // ActivateSystem activates a system
 func (av *AirVantage) ActivateSystem(system *System) (string, error) {
+	return av.ActivateSystemContext(context.Background(), system)
+}
 
-	url := av.URL("operations/systems/activate")
-	selection := struct {
-		Systems struct {
-			UIDs []string `json:"uids"`
-		} `json:"systems"`
-	}{}
-	selection.Systems.UIDs = []string{system.UID}
+// ActivateSystemContext is like ActivateSystem but uses ctx for the API calls.
+func (av *AirVantage) ActivateSystemContext(ctx context.Context, system *System) (string, error) {
+	return av.ActivateSystemBySelectionContext(ctx, SelectSystems(system.UID), nil)
+}
 
-	js, err := json.Marshal(selection)
-	if err != nil {
-		return "", err
-	}
-	slog.Debug("HTTP POST", "url", url, "json", string(js))
+// ActivateSystemBySelection activates the selected systems.
+// opts may be nil.
+func (av *AirVantage) ActivateSystemBySelection(sel SystemSelection, opts *OperationOptions) (string, error) {
+	return av.ActivateSystemBySelectionContext(context.Background(), sel, opts)
+}
 
-	resp, err := av.client.Post(url, "application/json", bytes.NewReader(js))
-	if err != nil {
-		return "", err
-	}
+// ActivateSystemBySelectionContext is like ActivateSystemBySelection but uses ctx for the API calls.
+func (av *AirVantage) ActivateSystemBySelectionContext(ctx context.Context, sel SystemSelection, opts *OperationOptions) (string, error) {
+	body := struct {
+		Systems SystemSelection `json:"systems"`
+	}{sel}
 
-	res := struct{ Operation string }{}
-	if err = av.parseResponse(resp, &res); err != nil {
-		return "", err
-	}
-	return string(res.Operation), nil
+	return av.launchOperation(ctx, "operations/systems/activate", sel, &body, opts)
 }
 
 // EditSystem updates the system
 func (av *AirVantage) EditSystem(uid string, system *System) (*System, error) {
+	return av.EditSystemContext(context.Background(), uid, system)
+}
+
+// EditSystemContext is like EditSystem but uses ctx for the API calls.
+func (av *AirVantage) EditSystemContext(ctx context.Context, uid string, system *System) (*System, error) {
 
 	url := av.URL("systems/" + uid)
 	js, err := json.Marshal(system)
 	if err != nil {
 		return nil, err
 	}
-	slog.Debug("HTTP POST", "url", url, "json", string(js))
+	av.logger().Debug("HTTP POST", "url", url, "json", string(js))
 
-	req, err := http.NewRequest("PUT", url, bytes.NewReader(js))
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewReader(js))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	resp, err := av.client.Do(req)
+	resp, err := av.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -224,32 +220,40 @@ func (av *AirVantage) EditSystem(uid string, system *System) (*System, error) {
 
 // DeleteSystem deletes a system and optionally its gateway and subscription.
 func (av *AirVantage) DeleteSystem(uid string, deleteGateway, deleteSubscription bool) error {
+	return av.DeleteSystemContext(context.Background(), uid, deleteGateway, deleteSubscription)
+}
+
+// DeleteSystemContext is like DeleteSystem but uses ctx for the API calls.
+func (av *AirVantage) DeleteSystemContext(ctx context.Context, uid string, deleteGateway, deleteSubscription bool) error {
 
 	url := av.URL("systems/"+uid, "deleteGateway", deleteGateway, "deleteSubscription", deleteSubscription)
-	slog.Debug("HTTP DELETE", "url", url)
+	av.logger().Debug("HTTP DELETE", "url", url)
 
-	req, err := http.NewRequest("DELETE", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
 	if err != nil {
 		return err
 	}
 
-	resp, err := av.client.Do(req)
+	resp, err := av.do(req)
 	if err != nil {
 		return err
 	}
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("%s: %s", url, resp.Status)
-	}
+	defer resp.Body.Close()
 
-	return nil
+	return av.parseError(resp)
 }
 
 // ExportDataFromDevices downloads a DataAggregate of all the devices for a given company,
 // in the given time interval. Optionally you can select the data points to return by
 // setting `fields`, a comma-separated list of data IDs.
 func (av *AirVantage) ExportDataFromDevices(companyUID, fields string, from, to time.Time) (DataAggregate, error) {
+	return av.ExportDataFromDevicesContext(context.Background(), companyUID, fields, from, to)
+}
 
-	resp, err := av.get("systems/data/fleet", "targetIds", companyUID, "dataIds", fields,
+// ExportDataFromDevicesContext is like ExportDataFromDevices but uses ctx for the API calls.
+func (av *AirVantage) ExportDataFromDevicesContext(ctx context.Context, companyUID, fields string, from, to time.Time) (DataAggregate, error) {
+
+	resp, err := av.get(ctx, "systems/data/fleet", "targetIds", companyUID, "dataIds", fields,
 		"from", NewAVTime(from), "to", NewAVTime(to))
 	if err != nil {
 		return nil, err
@@ -269,7 +273,14 @@ func (av *AirVantage) ExportDataFromDevices(companyUID, fields string, from, to
 // - fields is a comma-separated list of fields to return (optional)
 // - orderBy is a comma-separated list of fields to order the results (optional)
 // You can limit the number of results (100 by default) by adding a criteria 'size'.
+// It only returns the first page of results, use AllSystems to get all of them.
 func (av *AirVantage) FindSystems(criteria url.Values, fields, orderBy string) ([]System, error) {
+	return av.FindSystemsContext(context.Background(), criteria, fields, orderBy)
+}
+
+// FindSystemsContext is like FindSystems but uses ctx for the API calls.
+func (av *AirVantage) FindSystemsContext(ctx context.Context, criteria url.Values, fields, orderBy string) ([]System, error) {
+	criteria = cloneValues(criteria)
 	if fields != "" {
 		criteria.Set("fields", fields)
 	}
@@ -277,7 +288,7 @@ func (av *AirVantage) FindSystems(criteria url.Values, fields, orderBy string) (
 		criteria.Set("orderBy", orderBy)
 	}
 
-	resp, err := av.getWithParams("systems", criteria)
+	resp, err := av.getWithParams(ctx, "systems", criteria)
 	if err != nil {
 		return nil, err
 	}
@@ -292,15 +303,31 @@ func (av *AirVantage) FindSystems(criteria url.Values, fields, orderBy string) (
 	return page.Items, nil
 }
 
+// SystemPages returns the pages of the systems matching the criteria, see FindSystems.
+func (av *AirVantage) SystemPages(ctx context.Context, criteria url.Values, fields, orderBy string) *Pages[System] {
+	return newPages[System](ctx, av, "systems", criteria, fields, orderBy)
+}
+
+// AllSystems iterates over all the systems matching the criteria, see FindSystems.
+// Unlike FindSystems, it is not limited to one page of results.
+func (av *AirVantage) AllSystems(ctx context.Context, criteria url.Values, fields, orderBy string) iter.Seq2[System, error] {
+	return av.SystemPages(ctx, criteria, fields, orderBy).All()
+}
+
 // FindSystemByName returns the first System owning the given name.
 // Parameters:
 // - fields: a comma-separated list of fields to return (optional)
 func (av *AirVantage) FindSystemByName(name, fields string) (*System, error) {
+	return av.FindSystemByNameContext(context.Background(), name, fields)
+}
+
+// FindSystemByNameContext is like FindSystemByName but uses ctx for the API calls.
+func (av *AirVantage) FindSystemByNameContext(ctx context.Context, name, fields string) (*System, error) {
 	criteria := url.Values{}
 	criteria.Set("name", name)
 	criteria.Set("size", "1")
 
-	systems, err := av.FindSystems(criteria, fields, "")
+	systems, err := av.FindSystemsContext(ctx, criteria, fields, "")
 	if err != nil || len(systems) == 0 {
 		return nil, err
 	}
@@ -312,8 +339,13 @@ func (av *AirVantage) FindSystemByName(name, fields string) (*System, error) {
 // Parameters:
 // - fields: a comma-separated list of fields to return (optional)
 func (av *AirVantage) FindSystemByUID(UID string) (*System, error) {
+	return av.FindSystemByUIDContext(context.Background(), UID)
+}
 
-	resp, err := av.get("systems/" + UID)
+// FindSystemByUIDContext is like FindSystemByUID but uses ctx for the API calls.
+func (av *AirVantage) FindSystemByUIDContext(ctx context.Context, UID string) (*System, error) {
+
+	resp, err := av.get(ctx, "systems/"+UID)
 	if err != nil {
 		return nil, err
 	}
@@ -333,13 +365,18 @@ func (av *AirVantage) FindSystemByUID(UID string) (*System, error) {
 // - secuType: communication identifier SERIAL_NUMBER, IMEI, MAC_ADDRESS, PSK_IDENTITY, CUSTOM
 // - protocol: communication type MSCI, OMADM, AWTDA2, M3DA, REST, MQTT, LWM2M
 func (av *AirVantage) GetSystemSecurityInfo(authkey string, systemIdentifier string, secuType string, protocol string) (*SystemSecurityInfo, error) {
+	return av.GetSystemSecurityInfoContext(context.Background(), authkey, systemIdentifier, secuType, protocol)
+}
+
+// GetSystemSecurityInfoContext is like GetSystemSecurityInfo but uses ctx for the API calls.
+func (av *AirVantage) GetSystemSecurityInfoContext(ctx context.Context, authkey string, systemIdentifier string, secuType string, protocol string) (*SystemSecurityInfo, error) {
 
 	url := fmt.Sprintf("%s://%s/device/internal/securityinfo?id=%s&type=%s&protocol=%s&AUTHKEY=%s",
 		av.baseURLv1.Scheme, av.baseURLv1.Host, systemIdentifier, secuType, protocol, authkey)
-	req, _ := http.NewRequest("GET", url, nil)
+	req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
 	req.Header.Add("Content-Type", "application/json")
 
-	resp, err := av.client.Do(req)
+	resp, err := av.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -365,13 +402,18 @@ type TsValue struct {
 // GetLatestData V1 returns the latests data points on a device, without querying it. You can
 // optionally select which data to return by specifying a comma-separated list of data IDs.
 func (av *AirVantage) GetLatestData(systemUID, dataIDs string) (map[string][]TsValue, error) {
+	return av.GetLatestDataContext(context.Background(), systemUID, dataIDs)
+}
+
+// GetLatestDataContext is like GetLatestData but uses ctx for the API calls.
+func (av *AirVantage) GetLatestDataContext(ctx context.Context, systemUID, dataIDs string) (map[string][]TsValue, error) {
 	var err error
 	var resp *http.Response
 
 	if dataIDs != "" {
-		resp, err = av.get("systems/"+systemUID+"/data", "ids", dataIDs)
+		resp, err = av.get(ctx, "systems/"+systemUID+"/data", "ids", dataIDs)
 	} else {
-		resp, err = av.get("systems/" + systemUID + "/data")
+		resp, err = av.get(ctx, "systems/"+systemUID+"/data")
 	}
 	if err != nil {
 		return nil, err
@@ -385,6 +427,8 @@ func (av *AirVantage) GetLatestData(systemUID, dataIDs string) (map[string][]TsV
 	return res, nil
 }
 
+// TsValueV2 is a timestamped value, a number, a string or a boolean. See its
+// methods to get the value with its type.
 type TsValueV2 struct {
 	Value     any    `json:"v"`
 	Timestamp AVTime `json:"ts"`
@@ -393,13 +437,18 @@ type TsValueV2 struct {
 // GetLatestDataV2 returns the latests data points on a device, without querying it. You can
 // optionally select which data to return by specifying a comma-separated list of data IDs.
 func (av *AirVantage) GetLatestDataV2(systemUID, dataIDs string) (map[string][]TsValueV2, error) {
+	return av.GetLatestDataV2Context(context.Background(), systemUID, dataIDs)
+}
+
+// GetLatestDataV2Context is like GetLatestDataV2 but uses ctx for the API calls.
+func (av *AirVantage) GetLatestDataV2Context(ctx context.Context, systemUID, dataIDs string) (map[string][]TsValueV2, error) {
 	var err error
 	var resp *http.Response
 
 	if dataIDs != "" {
-		resp, err = av.getV2("systems/"+systemUID+"/data", "ids", dataIDs)
+		resp, err = av.getV2(ctx, "systems/"+systemUID+"/data", "ids", dataIDs)
 	} else {
-		resp, err = av.getV2("systems/" + systemUID + "/data")
+		resp, err = av.getV2(ctx, "systems/"+systemUID+"/data")
 	}
 	if err != nil {
 		return nil, err
@@ -430,8 +479,13 @@ type UnityConf struct {
 
 // GetUnityConfig returns the configuration of a Unity gateways (last datapoints, pending actions...)
 func (av *AirVantage) GetUnityConfig(systemUID string) (map[string]UnityConf, error) {
+	return av.GetUnityConfigContext(context.Background(), systemUID)
+}
 
-	resp, err := av.get("unity/" + systemUID + "/conf")
+// GetUnityConfigContext is like GetUnityConfig but uses ctx for the API calls.
+func (av *AirVantage) GetUnityConfigContext(ctx context.Context, systemUID string) (map[string]UnityConf, error) {
+
+	resp, err := av.get(ctx, "unity/"+systemUID+"/conf")
 	if err != nil {
 		return nil, err
 	}
@@ -453,8 +507,13 @@ type UnityCommand struct {
 
 // GetUnityCommand returns the command status of a Unity gateways
 func (av *AirVantage) GetUnityCommand(systemUID string) (map[string]UnityCommand, error) {
+	return av.GetUnityCommandContext(context.Background(), systemUID)
+}
 
-	resp, err := av.get("unity/" + systemUID + "/command")
+// GetUnityCommandContext is like GetUnityCommand but uses ctx for the API calls.
+func (av *AirVantage) GetUnityCommandContext(ctx context.Context, systemUID string) (map[string]UnityCommand, error) {
+
+	resp, err := av.get(ctx, "unity/"+systemUID+"/command")
 	if err != nil {
 		return nil, err
 	}
@@ -469,6 +528,11 @@ func (av *AirVantage) GetUnityCommand(systemUID string) (map[string]UnityCommand
 
 // DismissUnityCommand returns the command status of a Unity gateways
 func (av *AirVantage) DismissUnityCommand(systemUID string, commandID string) error {
+	return av.DismissUnityCommandContext(context.Background(), systemUID, commandID)
+}
+
+// DismissUnityCommandContext is like DismissUnityCommand but uses ctx for the API calls.
+func (av *AirVantage) DismissUnityCommandContext(ctx context.Context, systemUID string, commandID string) error {
 
 	body := struct {
 		CommandIDS []string `json:"commandIds"`
@@ -480,14 +544,15 @@ func (av *AirVantage) DismissUnityCommand(systemUID string, commandID string) er
 	}
 
 	url := av.URL("unity/" + systemUID + "/command/dismisserror")
-	slog.Debug("HTTP POST", "url", url, "json", string(js))
+	av.logger().Debug("HTTP POST", "url", url, "json", string(js))
 
-	_, err = av.client.Post(url, "application/json", bytes.NewReader(js))
+	resp, err := av.post(ctx, url, "application/json", bytes.NewReader(js))
 	if err != nil {
 		return err
 	}
+	defer resp.Body.Close()
 
-	return nil
+	return av.parseError(resp)
 }
 
 // ImportSystemsDefaults provides optional information to the
@@ -503,22 +568,53 @@ type ImportSystemsDefaults struct {
 	DefaultState string `json:"defaultState,omitempty"`
 	// Default system type.
 	DefaultType string `json:"defaultType,omitempty"`
+	// Options of the import operation. Its Notify and Callback take
+	// precedence over the fields above when set.
+	Options *OperationOptions `json:"-"`
 }
 
 // ImportSystems creates a batch of systems using data provided in CSV format.
 // To reduce repetitions in the CSV, you should provide `defaults` that will
 // be applied for each system. The default timeout is 5 minutes.
 func (av *AirVantage) ImportSystems(csv io.Reader, defaults *ImportSystemsDefaults, timeout time.Duration) error {
+	return av.ImportSystemsContext(context.Background(), csv, defaults, timeout)
+}
+
+// ImportSystemsContext is like ImportSystems but uses ctx for the API calls.
+func (av *AirVantage) ImportSystemsContext(ctx context.Context, csv io.Reader, defaults *ImportSystemsDefaults, timeout time.Duration) error {
+	if timeout == 0 {
+		timeout = 5 * time.Minute
+	}
+
+	res, err := av.ImportSystemsAwaitContext(ctx, csv, defaults, &AwaitOptions{Timeout: timeout, Interval: 5 * time.Second, Multiplier: 1})
+	if err != nil {
+		return err
+	}
+
+	// Check if all the systems were created.
+	if res.Counters.Failure > 0 {
+		return fmt.Errorf("failed to create %d systems", res.Counters.Failure)
+	}
+
+	return nil
+}
+
+// ImportSystemsAwait is like ImportSystems but waits for the import operation
+// with the given options, for example to follow its progress, and returns its
+// result. opts may be nil.
+func (av *AirVantage) ImportSystemsAwait(csv io.Reader, defaults *ImportSystemsDefaults, opts *AwaitOptions) (*OperationResult, error) {
+	return av.ImportSystemsAwaitContext(context.Background(), csv, defaults, opts)
+}
+
+// ImportSystemsAwaitContext is like ImportSystemsAwait but uses ctx for the API calls.
+func (av *AirVantage) ImportSystemsAwaitContext(ctx context.Context, csv io.Reader, defaults *ImportSystemsDefaults, opts *AwaitOptions) (*OperationResult, error) {
 
 	if csv == nil {
-		return fmt.Errorf("csv reader is nil")
+		return nil, fmt.Errorf("csv reader is nil")
 	}
 	if defaults == nil {
 		defaults = &ImportSystemsDefaults{}
 	}
-	if timeout == 0 {
-		timeout = 5 * time.Minute
-	}
 
 	// Create a multi-part request.
 	var bb bytes.Buffer
@@ -530,7 +626,7 @@ func (av *AirVantage) ImportSystems(csv io.Reader, defaults *ImportSystemsDefaul
 	header.Set("Content-Type", "text/csv")
 	partWriter, _ := multi.CreatePart(header)
 	if _, err := io.Copy(partWriter, csv); err != nil {
-		return fmt.Errorf("ImportSystems: %s", err)
+		return nil, fmt.Errorf("ImportSystems: %s", err)
 	}
 
 	// JSON part
@@ -538,117 +634,132 @@ func (av *AirVantage) ImportSystems(csv io.Reader, defaults *ImportSystemsDefaul
 	header.Set("Content-Disposition", `form-data; name="parameters"; filename="parameters.json"`)
 	header.Set("Content-Type", "application/json")
 	partWriter, _ = multi.CreatePart(header)
-	js, err := json.Marshal(defaults)
+	js, err := marshalLaunch(defaults, defaults.Options)
 	if err != nil {
-		return fmt.Errorf("ImportSystems: %s", err)
+		return nil, fmt.Errorf("ImportSystems: %s", err)
 	}
 	partWriter.Write(js)
 
 	multi.Close()
 
-	req, err := http.NewRequest("POST", av.URL("operations/systems/import"), &bb)
+	req, err := http.NewRequestWithContext(ctx, "POST", av.URL("operations/systems/import"), &bb)
 	if err != nil {
-		return fmt.Errorf("ImportSystems: %s", err)
+		return nil, fmt.Errorf("ImportSystems: %s", err)
 	}
 	req.Header.Set("Content-Type", multi.FormDataContentType())
 
-	resp, err := av.client.Do(req)
+	resp, err := av.do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	res := struct{ Operation string }{}
 	if err = av.parseResponse(resp, &res); err != nil {
-		return err
+		return nil, err
 	}
 	// Waiting for operation to finish
-	slog.Debug("waiting for systems import operation", "uid", res.Operation)
+	av.logger().Debug("waiting for systems import operation", "uid", res.Operation)
 
-	op, err := av.AwaitOperation(res.Operation, timeout)
-	if err != nil {
-		return err
-	}
-
-	// Check if all the systems were created.
-	if op.Counters.Failure > 0 {
-		return fmt.Errorf("failed to create %d systems", op.Counters.Failure)
-	}
-
-	return nil
+	return av.AwaitOperationResultContext(ctx, res.Operation, opts)
 }
 
 // InstallApplication installs or upgrades an application on a system
 func (av *AirVantage) InstallApplication(appUID, systemUID string) (string, error) {
+	return av.InstallApplicationContext(context.Background(), appUID, systemUID)
+}
 
-	type jsonBody struct {
-		Systems struct {
-			UIDs []string `json:"uids"`
-		} `json:"systems"`
-		Application string `json:"application"`
-	}
-	var body jsonBody
-	body.Systems.UIDs = []string{systemUID}
-	body.Application = appUID
+// InstallApplicationContext is like InstallApplication but uses ctx for the API calls.
+func (av *AirVantage) InstallApplicationContext(ctx context.Context, appUID, systemUID string) (string, error) {
+	return av.InstallApplicationBySelectionContext(ctx, appUID, SelectSystems(systemUID), nil)
+}
 
-	js, err := json.Marshal(&body)
-	if err != nil {
-		return "", err
-	}
+// InstallApplicationBySelection installs or upgrades an application on the selected systems.
+// opts may be nil.
+func (av *AirVantage) InstallApplicationBySelection(appUID string, sel SystemSelection, opts *OperationOptions) (string, error) {
+	return av.InstallApplicationBySelectionContext(context.Background(), appUID, sel, opts)
+}
 
-	url := av.URL("operations/systems/applications/install")
-	slog.Debug("HTTP POST", "url", url, "json", string(js))
+// InstallApplicationBySelectionContext is like InstallApplicationBySelection but uses ctx for the API calls.
+func (av *AirVantage) InstallApplicationBySelectionContext(ctx context.Context, appUID string, sel SystemSelection, opts *OperationOptions) (string, error) {
+	body := struct {
+		Systems     SystemSelection `json:"systems"`
+		Application string          `json:"application"`
+	}{sel, appUID}
 
-	resp, err := av.client.Post(url, "application/json", bytes.NewReader(js))
-	if err != nil {
-		return "", err
-	}
+	return av.launchOperation(ctx, "operations/systems/applications/install", sel, &body, opts)
+}
 
-	res := struct{ Operation string }{}
-	if err = av.parseResponse(resp, &res); err != nil {
-		return "", err
-	}
-	return string(res.Operation), nil
+// UninstallApplication removes an application from a system.
+func (av *AirVantage) UninstallApplication(appUID, systemUID string) (string, error) {
+	return av.UninstallApplicationContext(context.Background(), appUID, systemUID)
+}
+
+// UninstallApplicationContext is like UninstallApplication but uses ctx for the API calls.
+func (av *AirVantage) UninstallApplicationContext(ctx context.Context, appUID, systemUID string) (string, error) {
+	return av.UninstallApplicationBySelectionContext(ctx, appUID, SelectSystems(systemUID), nil)
+}
+
+// UninstallApplicationBySelection removes an application from the selected systems.
+// opts may be nil.
+func (av *AirVantage) UninstallApplicationBySelection(appUID string, sel SystemSelection, opts *OperationOptions) (string, error) {
+	return av.UninstallApplicationBySelectionContext(context.Background(), appUID, sel, opts)
+}
+
+// UninstallApplicationBySelectionContext is like UninstallApplicationBySelection but uses ctx for the API calls.
+func (av *AirVantage) UninstallApplicationBySelectionContext(ctx context.Context, appUID string, sel SystemSelection, opts *OperationOptions) (string, error) {
+	body := struct {
+		Systems     SystemSelection `json:"systems"`
+		Application string          `json:"application"`
+	}{sel, appUID}
+
+	return av.launchOperation(ctx, "operations/systems/applications/uninstall", sel, &body, opts)
 }
 
 // RetrieveData launch an operation to read the given paths on the system
 func (av *AirVantage) RetrieveData(paths []string, protocol string, systemUID string) (string, error) {
+	return av.RetrieveDataContext(context.Background(), paths, protocol, systemUID)
+}
 
-	type jsonBody struct {
-		Systems struct {
-			UIDs []string `json:"uids"`
-		} `json:"systems"`
-		Data     []string `json:"data"`
-		Protocol string   `json:"protocol"`
-	}
-	var body jsonBody
-	body.Systems.UIDs = []string{systemUID}
-	body.Data = paths
-	if protocol != "" {
-		body.Protocol = protocol
-	}
-
-	js, err := json.Marshal(&body)
-	if err != nil {
-		return "", err
-	}
+// RetrieveDataContext is like RetrieveData but uses ctx for the API calls.
+func (av *AirVantage) RetrieveDataContext(ctx context.Context, paths []string, protocol string, systemUID string) (string, error) {
+	return av.RetrieveDataBySelectionContext(ctx, paths, protocol, SelectSystems(systemUID), nil)
+}
 
-	url := av.URL("operations/systems/data/retrieve")
-	slog.Debug("HTTP POST", "url", url, "json", string(js))
+// RetrieveDataBySelection launches an operation to read the given paths on the selected systems.
+// opts may be nil.
+func (av *AirVantage) RetrieveDataBySelection(paths []string, protocol string, sel SystemSelection, opts *OperationOptions) (string, error) {
+	return av.RetrieveDataBySelectionContext(context.Background(), paths, protocol, sel, opts)
+}
 
-	resp, err := av.client.Post(url, "application/json", bytes.NewReader(js))
-	if err != nil {
-		return "", err
-	}
+// RetrieveDataBySelectionContext is like RetrieveDataBySelection but uses ctx for the API calls.
+func (av *AirVantage) RetrieveDataBySelectionContext(ctx context.Context, paths []string, protocol string, sel SystemSelection, opts *OperationOptions) (string, error) {
+	body := struct {
+		Systems  SystemSelection `json:"systems"`
+		Data     []string        `json:"data"`
+		Protocol string          `json:"protocol"`
+	}{sel, paths, protocol}
 
-	res := struct{ Operation string }{}
-	if err = av.parseResponse(resp, &res); err != nil {
-		return "", err
-	}
-	return string(res.Operation), nil
+	return av.launchOperation(ctx, "operations/systems/data/retrieve", sel, &body, opts)
 }
 
 // Configure Communication launch an operation to configure the communication on the system.
 func (av *AirVantage) ConfigureCommunication(hbState string, hbPeriod int, srState string, srPeriod int, systemsUID []string, reports []AdvancedReports) (string, error) {
+	return av.ConfigureCommunicationContext(context.Background(), hbState, hbPeriod, srState, srPeriod, systemsUID, reports)
+}
+
+// ConfigureCommunicationContext is like ConfigureCommunication but uses ctx for the API calls.
+func (av *AirVantage) ConfigureCommunicationContext(ctx context.Context, hbState string, hbPeriod int, srState string, srPeriod int, systemsUID []string, reports []AdvancedReports) (string, error) {
+	return av.ConfigureCommunicationBySelectionContext(ctx, hbState, hbPeriod, srState, srPeriod, SelectSystems(systemsUID...), reports, nil)
+}
+
+// ConfigureCommunicationBySelection launches an operation to configure the communication on the selected systems.
+// opts may be nil.
+func (av *AirVantage) ConfigureCommunicationBySelection(hbState string, hbPeriod int, srState string, srPeriod int, sel SystemSelection, reports []AdvancedReports, opts *OperationOptions) (string, error) {
+	return av.ConfigureCommunicationBySelectionContext(context.Background(), hbState, hbPeriod, srState, srPeriod, sel, reports, opts)
+}
+
+// ConfigureCommunicationBySelectionContext is like ConfigureCommunicationBySelection but uses ctx for the API calls.
+func (av *AirVantage) ConfigureCommunicationBySelectionContext(ctx context.Context, hbState string, hbPeriod int, srState string, srPeriod int, sel SystemSelection, reports []AdvancedReports, opts *OperationOptions) (string, error) {
 
 	type HeartBeat struct {
 		State      string `json:"state"`
@@ -662,54 +773,38 @@ func (av *AirVantage) ConfigureCommunication(hbState string, hbPeriod int, srSta
 	}
 
 	type jsonBody struct {
-		Systems struct {
-			UIDs []string `json:"uids"`
-		} `json:"systems"`
+		Systems         SystemSelection   `json:"systems"`
 		HeartBeat       HeartBeat         `json:"heartbeat"`
 		StatusReport    StatusReport      `json:"statusReport"`
 		AdvancedReports []AdvancedReports `json:"reports"`
 	}
 
 	var body jsonBody
-	if len(systemsUID) > 0 {
-		body.Systems.UIDs = systemsUID
-		if hbPeriod != 0 {
-			body.HeartBeat.State = hbState
-			body.HeartBeat.Period = hbPeriod
-		}
-
-		if srPeriod != 0 {
-			body.StatusReport.State = srState
-			body.StatusReport.Period = srPeriod
-		}
-
-		if len(reports) > 0 {
-			body.AdvancedReports = reports
-		}
+	body.Systems = sel
+	if hbPeriod != 0 {
+		body.HeartBeat.State = hbState
+		body.HeartBeat.Period = hbPeriod
 	}
-	body.HeartBeat.ServerOnly = false
 
-	js, err := json.Marshal(&body)
-	if err != nil {
-		return "", err
+	if srPeriod != 0 {
+		body.StatusReport.State = srState
+		body.StatusReport.Period = srPeriod
 	}
 
-	ccUrl := av.URL("operations/systems/configure")
-	slog.Debug("HTTP POST", "url", ccUrl, "json", string(js))
-
-	resp, err := av.client.Post(ccUrl, "application/json", bytes.NewReader(js))
-	if err != nil {
-		return "", err
+	if len(reports) > 0 {
+		body.AdvancedReports = reports
 	}
+	body.HeartBeat.ServerOnly = false
 
-	res := struct{ Operation string }{}
-	if err = av.parseResponse(resp, &res); err != nil {
-		return "", err
-	}
-	return string(res.Operation), nil
+	return av.launchOperation(ctx, "operations/systems/configure", sel, &body, opts)
 }
 
 func (av *AirVantage) CreateDataset(name string, description string, configuration []string, appId string) (*DataSet, error) {
+	return av.CreateDatasetContext(context.Background(), name, description, configuration, appId)
+}
+
+// CreateDatasetContext is like CreateDataset but uses ctx for the API calls.
+func (av *AirVantage) CreateDatasetContext(ctx context.Context, name string, description string, configuration []string, appId string) (*DataSet, error) {
 
 	var dataset DataSet
 	dataset.Info.Name = name
@@ -723,41 +818,55 @@ func (av *AirVantage) CreateDataset(name string, description string, configurati
 	}
 
 	ccUrl := av.URL("/api/v2/datasets")
-	slog.Debug("HTTP POST", "url", ccUrl, "json", string(js))
+	av.logger().Debug("HTTP POST", "url", ccUrl, "json", string(js))
 
-	resp, err := av.client.Post(ccUrl, "application/json", bytes.NewReader(js))
+	resp, err := av.post(ctx, ccUrl, "application/json", bytes.NewReader(js))
 	if err != nil {
 		return nil, err
 	}
-	slog.Info("Create dataset response", "resp", resp)
+	av.logger().Info("Create dataset response", "resp", resp)
 
 	res := &DataSet{}
 	if err = av.parseResponse(resp, &res); err != nil {
 		return nil, err
 	}
-	slog.Info("Create dataset response parsed", "res", res)
+	av.logger().Info("Create dataset response parsed", "res", res)
 
 	return res, nil
 }
 
 // ApplySettings launch an operation to write/delete the given settings on the system
 func (av *AirVantage) ApplySettings(settings map[string]any, delete []string, protocol, systemUID string) (string, error) {
+	return av.ApplySettingsContext(context.Background(), settings, delete, protocol, systemUID)
+}
+
+// ApplySettingsContext is like ApplySettings but uses ctx for the API calls.
+func (av *AirVantage) ApplySettingsContext(ctx context.Context, settings map[string]any, delete []string, protocol, systemUID string) (string, error) {
+	return av.ApplySettingsBySelectionContext(ctx, settings, delete, protocol, SelectSystems(systemUID), nil)
+}
+
+// ApplySettingsBySelection launches an operation to write/delete the given settings on the selected systems.
+// opts may be nil.
+func (av *AirVantage) ApplySettingsBySelection(settings map[string]any, delete []string, protocol string, sel SystemSelection, opts *OperationOptions) (string, error) {
+	return av.ApplySettingsBySelectionContext(context.Background(), settings, delete, protocol, sel, opts)
+}
+
+// ApplySettingsBySelectionContext is like ApplySettingsBySelection but uses ctx for the API calls.
+func (av *AirVantage) ApplySettingsBySelectionContext(ctx context.Context, settings map[string]any, delete []string, protocol string, sel SystemSelection, opts *OperationOptions) (string, error) {
 
 	type Setting struct {
 		Key   string `json:"key"`
 		Value any    `json:"value"`
 	}
 	type jsonBody struct {
-		Systems struct {
-			UIDs []string `json:"uids"`
-		} `json:"systems"`
-		Settings []Setting `json:"settings"`
-		Delete   []string  `json:"deleteSettings"`
-		Protocol string    `json:"protocol"`
-		Reboot   bool      `json:"reboot"`
+		Systems  SystemSelection `json:"systems"`
+		Settings []Setting       `json:"settings"`
+		Delete   []string        `json:"deleteSettings"`
+		Protocol string          `json:"protocol"`
+		Reboot   bool            `json:"reboot"`
 	}
 	var body jsonBody
-	body.Systems.UIDs = []string{systemUID}
+	body.Systems = sel
 	body.Settings = make([]Setting, len(settings))
 	if len(delete) > 0 {
 		body.Delete = delete
@@ -770,171 +879,120 @@ func (av *AirVantage) ApplySettings(settings map[string]any, delete []string, pr
 	if protocol != "" {
 		body.Protocol = protocol
 	}
-	body.Reboot = false
-
-	js, err := json.Marshal(&body)
-	if err != nil {
-		return "", err
-	}
-
-	url := av.URL("operations/systems/settings")
-	slog.Debug("HTTP POST", "url", url, "json", string(js))
-
-	resp, err := av.client.Post(url, "application/json", bytes.NewReader(js))
-	if err != nil {
-		return "", err
-	}
+	body.Reboot = opts != nil && opts.Reboot
 
-	res := struct{ Operation string }{}
-	if err = av.parseResponse(resp, &res); err != nil {
-		return "", err
-	}
-	return string(res.Operation), nil
+	return av.launchOperation(ctx, "operations/systems/settings", sel, &body, opts)
 }
 
 // SendCommand launch an operation to run the given command and parameters on the system
 func (av *AirVantage) SendCommand(commandID string, parameters map[string]any, protocol, systemUID string) (string, error) {
+	return av.SendCommandContext(context.Background(), commandID, parameters, protocol, systemUID)
+}
 
-	type jsonBody struct {
-		Systems struct {
-			UIDs []string `json:"uids"`
-		} `json:"systems"`
-		CommandID  string         `json:"commandId"`
-		Parameters map[string]any `json:"parameters"`
-		Protocol   string         `json:"protocol"`
-	}
-	var body jsonBody
-	body.Systems.UIDs = []string{systemUID}
-	body.CommandID = commandID
-	body.Parameters = parameters
-
-	if protocol != "" {
-		body.Protocol = protocol
-	}
-
-	js, err := json.Marshal(&body)
-	if err != nil {
-		return "", err
-	}
+// SendCommandContext is like SendCommand but uses ctx for the API calls.
+func (av *AirVantage) SendCommandContext(ctx context.Context, commandID string, parameters map[string]any, protocol, systemUID string) (string, error) {
+	return av.SendCommandBySelectionContext(ctx, commandID, parameters, protocol, SelectSystems(systemUID), nil)
+}
 
-	url := av.URL("operations/systems/command")
-	slog.Debug("HTTP POST", "url", url, "json", string(js))
+// SendCommandBySelection launches an operation to run the given command and parameters on the selected systems.
+// opts may be nil.
+func (av *AirVantage) SendCommandBySelection(commandID string, parameters map[string]any, protocol string, sel SystemSelection, opts *OperationOptions) (string, error) {
+	return av.SendCommandBySelectionContext(context.Background(), commandID, parameters, protocol, sel, opts)
+}
 
-	resp, err := av.client.Post(url, "application/json", bytes.NewReader(js))
-	if err != nil {
-		return "", err
-	}
+// SendCommandBySelectionContext is like SendCommandBySelection but uses ctx for the API calls.
+func (av *AirVantage) SendCommandBySelectionContext(ctx context.Context, commandID string, parameters map[string]any, protocol string, sel SystemSelection, opts *OperationOptions) (string, error) {
+	body := struct {
+		Systems    SystemSelection `json:"systems"`
+		CommandID  string          `json:"commandId"`
+		Parameters map[string]any  `json:"parameters"`
+		Protocol   string          `json:"protocol"`
+	}{sel, commandID, parameters, protocol}
 
-	res := struct{ Operation string }{}
-	if err = av.parseResponse(resp, &res); err != nil {
-		return "", err
-	}
-	return string(res.Operation), nil
+	return av.launchOperation(ctx, "operations/systems/command", sel, &body, opts)
 }
 
 // SendFile launches an operation to send the given file to a system
 func (av *AirVantage) SendFile(fileID, target, systemUID string) (string, error) {
+	return av.SendFileContext(context.Background(), fileID, target, systemUID)
+}
 
-	type jsonBody struct {
-		Systems struct {
-			UIDs []string `json:"uids"`
-		} `json:"systems"`
-		FileID string `json:"file"`
-		Target string `json:"target"`
-	}
-	var body jsonBody
-	body.Systems.UIDs = []string{systemUID}
-	body.FileID = fileID
-	body.Target = target
-
-	js, err := json.Marshal(&body)
-	if err != nil {
-		return "", err
-	}
+// SendFileContext is like SendFile but uses ctx for the API calls.
+func (av *AirVantage) SendFileContext(ctx context.Context, fileID, target, systemUID string) (string, error) {
+	return av.SendFileBySelectionContext(ctx, fileID, target, SelectSystems(systemUID), nil)
+}
 
-	url := av.URL("operations/systems/file/send")
-	slog.Debug("HTTP POST", "url", url, "json", string(js))
+// SendFileBySelection launches an operation to send the given file to the selected systems.
+// opts may be nil.
+func (av *AirVantage) SendFileBySelection(fileID, target string, sel SystemSelection, opts *OperationOptions) (string, error) {
+	return av.SendFileBySelectionContext(context.Background(), fileID, target, sel, opts)
+}
 
-	resp, err := av.client.Post(url, "application/json", bytes.NewReader(js))
-	if err != nil {
-		return "", err
-	}
+// SendFileBySelectionContext is like SendFileBySelection but uses ctx for the API calls.
+func (av *AirVantage) SendFileBySelectionContext(ctx context.Context, fileID, target string, sel SystemSelection, opts *OperationOptions) (string, error) {
+	body := struct {
+		Systems SystemSelection `json:"systems"`
+		FileID  string          `json:"file"`
+		Target  string          `json:"target"`
+	}{sel, fileID, target}
 
-	res := struct{ Operation string }{}
-	if err = av.parseResponse(resp, &res); err != nil {
-		return "", err
-	}
-	return string(res.Operation), nil
+	return av.launchOperation(ctx, "operations/systems/file/send", sel, &body, opts)
 }
 
 // Reboot launch an operation to run a reboot on the given system
 func (av *AirVantage) Reboot(action string, systemUID string) (string, error) {
+	return av.RebootContext(context.Background(), action, systemUID)
+}
 
-	type jsonBody struct {
-		Systems struct {
-			UIDs []string `json:"uids"`
-		} `json:"systems"`
-		Action *string `json:"action"` //optional, null by default
-	}
-	var body jsonBody
-	body.Systems.UIDs = []string{systemUID}
+// RebootContext is like Reboot but uses ctx for the API calls.
+func (av *AirVantage) RebootContext(ctx context.Context, action string, systemUID string) (string, error) {
+	return av.RebootBySelectionContext(ctx, action, SelectSystems(systemUID), nil)
+}
+
+// RebootBySelection launches an operation to run a reboot on the selected systems.
+// opts may be nil.
+func (av *AirVantage) RebootBySelection(action string, sel SystemSelection, opts *OperationOptions) (string, error) {
+	return av.RebootBySelectionContext(context.Background(), action, sel, opts)
+}
 
+// RebootBySelectionContext is like RebootBySelection but uses ctx for the API calls.
+func (av *AirVantage) RebootBySelectionContext(ctx context.Context, action string, sel SystemSelection, opts *OperationOptions) (string, error) {
+	body := struct {
+		Systems SystemSelection `json:"systems"`
+		Action  *string         `json:"action"` //optional, null by default
+	}{Systems: sel}
 	if action != "" {
 		body.Action = &action
 	}
 
-	js, err := json.Marshal(&body)
-	if err != nil {
-		return "", err
-	}
-
-	url := av.URL("operations/systems/reboot")
-	slog.Debug("HTTP POST", "url", url, "json", string(js))
-
-	resp, err := av.client.Post(url, "application/json", bytes.NewReader(js))
-	if err != nil {
-		return "", err
-	}
-
-	res := struct{ Operation string }{}
-	if err = av.parseResponse(resp, &res); err != nil {
-		return "", err
-	}
-	return string(res.Operation), nil
+	return av.launchOperation(ctx, "operations/systems/reboot", sel, &body, opts)
 }
 
 // Reset launch an operation to run a factory Reset on the given system
 func (av *AirVantage) Reset(action string, systemUID string) (string, error) {
+	return av.ResetContext(context.Background(), action, systemUID)
+}
 
-	type jsonBody struct {
-		Systems struct {
-			UIDs []string `json:"uids"`
-		} `json:"systems"`
-		Action *string `json:"action"` //optional, null by default
-	}
-	var body jsonBody
-	body.Systems.UIDs = []string{systemUID}
+// ResetContext is like Reset but uses ctx for the API calls.
+func (av *AirVantage) ResetContext(ctx context.Context, action string, systemUID string) (string, error) {
+	return av.ResetBySelectionContext(ctx, action, SelectSystems(systemUID), nil)
+}
+
+// ResetBySelection launches an operation to run a factory Reset on the selected systems.
+// opts may be nil.
+func (av *AirVantage) ResetBySelection(action string, sel SystemSelection, opts *OperationOptions) (string, error) {
+	return av.ResetBySelectionContext(context.Background(), action, sel, opts)
+}
 
+// ResetBySelectionContext is like ResetBySelection but uses ctx for the API calls.
+func (av *AirVantage) ResetBySelectionContext(ctx context.Context, action string, sel SystemSelection, opts *OperationOptions) (string, error) {
+	body := struct {
+		Systems SystemSelection `json:"systems"`
+		Action  *string         `json:"action"` //optional, null by default
+	}{Systems: sel}
 	if action != "" {
 		body.Action = &action
 	}
 
-	js, err := json.Marshal(&body)
-	if err != nil {
-		return "", err
-	}
-
-	url := av.URL("operations/systems/reset")
-	slog.Debug("HTTP POST", "url", url, "json", string(js))
-
-	resp, err := av.client.Post(url, "application/json", bytes.NewReader(js))
-	if err != nil {
-		return "", err
-	}
-
-	res := struct{ Operation string }{}
-	if err = av.parseResponse(resp, &res); err != nil {
-		return "", err
-	}
-	return string(res.Operation), nil
+	return av.launchOperation(ctx, "operations/systems/reset", sel, &body, opts)
 }
@@ -0,0 +1,37 @@
+package airvantage_test
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+func TestUninstallApplication(t *testing.T) {
+	srv, av := newServer(t)
+
+	single, err := av.UninstallApplication("app", "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	selected, err := av.UninstallApplicationBySelection("app", airvantage.SelectSystems("s1", "s2"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for uid, systems := range map[string][]string{single: {"s1"}, selected: {"s1", "s2"}} {
+		op, ok := srv.Operation(uid)
+		if !ok || op.Endpoint != "systems/applications/uninstall" || op.Type != airvantage.OperationTypeUninstallApplication ||
+			!slices.Equal(op.Systems, systems) {
+			t.Errorf("invalid operation %+v", op)
+		}
+
+		var body struct {
+			Application string `json:"application"`
+		}
+		if err = json.Unmarshal(op.Body, &body); err != nil || body.Application != "app" {
+			t.Errorf("expected the application in the request, got: %s", op.Body)
+		}
+	}
+}
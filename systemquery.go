@@ -0,0 +1,303 @@
+package airvantage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LifeCycleState is the life cycle state of a System.
+type LifeCycleState string
+
+const (
+	LifeCycleInventory LifeCycleState = "INVENTORY"
+	LifeCycleReady     LifeCycleState = "READY"
+	LifeCycleDeployed  LifeCycleState = "DEPLOYED"
+	LifeCycleSuspended LifeCycleState = "SUSPENDED"
+	LifeCycleRetired   LifeCycleState = "RETIRED"
+)
+
+// CommStatus is the communication status of a System.
+type CommStatus string
+
+const (
+	CommStatusOK        CommStatus = "OK"
+	CommStatusWarning   CommStatus = "WARNING"
+	CommStatusError     CommStatus = "ERROR"
+	CommStatusUndefined CommStatus = "UNDEFINED"
+)
+
+var (
+	lifeCycleStates = []LifeCycleState{LifeCycleInventory, LifeCycleReady, LifeCycleDeployed, LifeCycleSuspended, LifeCycleRetired}
+	commStatuses    = []CommStatus{CommStatusOK, CommStatusWarning, CommStatusError, CommStatusUndefined}
+)
+
+// SystemQuery builds the criteria of a systems search. The methods return the
+// query itself so that calls can be chained:
+//
+//	q := NewSystemQuery().AllLabels("fleet", "v2").CommStatuses(CommStatusError).OrderBy("lastCommDate", true)
+type SystemQuery struct {
+	name        string
+	uids        []string
+	labelGroups [][]string
+	states      []LifeCycleState
+	commStatus  []CommStatus
+	gateway     []string
+	lastComm    timeRange
+	creation    timeRange
+	orderBy     []string
+	fields      []string
+	size        int
+	offset      int
+}
+
+type timeRange struct {
+	from, to AVTime
+}
+
+// String formats the range as "from,to", where a zero bound is left empty.
+func (r timeRange) String() string {
+	bound := func(t AVTime) string {
+		if t == 0 {
+			return ""
+		}
+		return strconv.FormatInt(int64(t), 10)
+	}
+	return bound(r.from) + "," + bound(r.to)
+}
+
+func (r timeRange) isZero() bool {
+	return r.from == 0 && r.to == 0
+}
+
+// NewSystemQuery returns an empty query, matching all the systems.
+func NewSystemQuery() *SystemQuery {
+	return &SystemQuery{}
+}
+
+// Name filters the systems by name.
+func (q *SystemQuery) Name(name string) *SystemQuery {
+	q.name = name
+	return q
+}
+
+// UIDs filters the systems by UID.
+func (q *SystemQuery) UIDs(uids ...string) *SystemQuery {
+	q.uids = append(q.uids, uids...)
+	return q
+}
+
+// AnyLabels keeps the systems having at least one of the labels.
+func (q *SystemQuery) AnyLabels(labels ...string) *SystemQuery {
+	q.labelGroups = append(q.labelGroups, labels)
+	return q
+}
+
+// AllLabels keeps the systems having all the labels.
+func (q *SystemQuery) AllLabels(labels ...string) *SystemQuery {
+	for _, label := range labels {
+		q.labelGroups = append(q.labelGroups, []string{label})
+	}
+	return q
+}
+
+// LifeCycleStates keeps the systems in one of the states.
+func (q *SystemQuery) LifeCycleStates(states ...LifeCycleState) *SystemQuery {
+	q.states = append(q.states, states...)
+	return q
+}
+
+// CommStatuses keeps the systems with one of the communication statuses.
+func (q *SystemQuery) CommStatuses(statuses ...CommStatus) *SystemQuery {
+	q.commStatus = append(q.commStatus, statuses...)
+	return q
+}
+
+// IMEI looks for the system whose gateway has the given IMEI.
+func (q *SystemQuery) IMEI(imei string) *SystemQuery {
+	q.gateway = append(q.gateway, "imei:"+imei)
+	return q
+}
+
+// SerialNumber looks for the system whose gateway has the given serial number.
+func (q *SystemQuery) SerialNumber(serialNumber string) *SystemQuery {
+	q.gateway = append(q.gateway, "serialNumber:"+serialNumber)
+	return q
+}
+
+// LastCommBetween keeps the systems whose last communication happened in the
+// given range. A zero bound leaves the range open on that side.
+func (q *SystemQuery) LastCommBetween(from, to AVTime) *SystemQuery {
+	q.lastComm = timeRange{from, to}
+	return q
+}
+
+// CreatedBetween keeps the systems created in the given range. A zero bound
+// leaves the range open on that side.
+func (q *SystemQuery) CreatedBetween(from, to AVTime) *SystemQuery {
+	q.creation = timeRange{from, to}
+	return q
+}
+
+// OrderBy sorts the results by the given field. It can be called several
+// times to sort by several fields.
+func (q *SystemQuery) OrderBy(field string, desc bool) *SystemQuery {
+	if desc {
+		field += ":desc"
+	} else {
+		field += ":asc"
+	}
+	q.orderBy = append(q.orderBy, field)
+	return q
+}
+
+// Fields selects the fields returned for each system.
+func (q *SystemQuery) Fields(fields ...string) *SystemQuery {
+	q.fields = append(q.fields, fields...)
+	return q
+}
+
+// Size sets the number of results per page.
+func (q *SystemQuery) Size(size int) *SystemQuery {
+	q.size = size
+	return q
+}
+
+// Offset sets the index of the first result.
+func (q *SystemQuery) Offset(offset int) *SystemQuery {
+	q.offset = offset
+	return q
+}
+
+// Validate checks the consistency of the query.
+func (q *SystemQuery) Validate() error {
+	var errs []error
+
+	for _, state := range q.states {
+		if !slices.Contains(lifeCycleStates, state) {
+			errs = append(errs, fmt.Errorf("unknown life cycle state '%s'", state))
+		}
+	}
+	for _, status := range q.commStatus {
+		if !slices.Contains(commStatuses, status) {
+			errs = append(errs, fmt.Errorf("unknown communication status '%s'", status))
+		}
+	}
+	for _, group := range q.labelGroups {
+		if len(group) == 0 || slices.Contains(group, "") {
+			errs = append(errs, errors.New("empty label"))
+		}
+	}
+	if len(q.gateway) > 1 {
+		errs = append(errs, errors.New("only one gateway identifier (IMEI or serial number) can be searched"))
+	}
+	if q.lastComm.from != 0 && q.lastComm.to != 0 && q.lastComm.from > q.lastComm.to {
+		errs = append(errs, errors.New("last communication range ends before it starts"))
+	}
+	if q.creation.from != 0 && q.creation.to != 0 && q.creation.from > q.creation.to {
+		errs = append(errs, errors.New("creation range ends before it starts"))
+	}
+	if slices.Contains(q.fields, "") {
+		errs = append(errs, errors.New("empty field name"))
+	}
+	for _, order := range q.orderBy {
+		if strings.HasPrefix(order, ":") {
+			errs = append(errs, errors.New("empty order by field"))
+		}
+	}
+	if q.size < 0 {
+		errs = append(errs, fmt.Errorf("invalid size %d", q.size))
+	}
+	if q.offset < 0 {
+		errs = append(errs, fmt.Errorf("invalid offset %d", q.offset))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Values validates the query and returns the matching search criteria.
+// Each label group is sent as a 'labels' parameter: the labels of a group
+// are alternatives, while all the groups must match.
+func (q *SystemQuery) Values() (url.Values, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	v := url.Values{}
+	if q.name != "" {
+		v.Set("name", q.name)
+	}
+	if len(q.uids) > 0 {
+		v.Set("uid", strings.Join(q.uids, ","))
+	}
+	for _, group := range q.labelGroups {
+		v.Add("labels", strings.Join(group, ","))
+	}
+	if len(q.states) > 0 {
+		v.Set("lifeCycleState", joinStrings(q.states))
+	}
+	if len(q.commStatus) > 0 {
+		v.Set("commStatus", joinStrings(q.commStatus))
+	}
+	if len(q.gateway) > 0 {
+		v.Set("gateway", q.gateway[0])
+	}
+	if !q.lastComm.isZero() {
+		v.Set("lastCommDate", q.lastComm.String())
+	}
+	if !q.creation.isZero() {
+		v.Set("creationDate", q.creation.String())
+	}
+	if len(q.orderBy) > 0 {
+		v.Set("orderBy", strings.Join(q.orderBy, ","))
+	}
+	if len(q.fields) > 0 {
+		v.Set("fields", strings.Join(q.fields, ","))
+	}
+	if q.size > 0 {
+		v.Set("size", strconv.Itoa(q.size))
+	}
+	if q.offset > 0 {
+		v.Set("offset", strconv.Itoa(q.offset))
+	}
+
+	return v, nil
+}
+
+func joinStrings[S ~string](values []S) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = string(v)
+	}
+	return strings.Join(s, ",")
+}
+
+// FindSystemsByQuery returns the first page of the systems matching the query.
+func (av *AirVantage) FindSystemsByQuery(q *SystemQuery) ([]System, error) {
+	return av.FindSystemsByQueryContext(context.Background(), q)
+}
+
+// FindSystemsByQueryContext is like FindSystemsByQuery but uses ctx for the API calls.
+func (av *AirVantage) FindSystemsByQueryContext(ctx context.Context, q *SystemQuery) ([]System, error) {
+	criteria, err := q.Values()
+	if err != nil {
+		return nil, err
+	}
+	return av.FindSystemsContext(ctx, criteria, "", "")
+}
+
+// AllSystemsByQuery iterates over all the systems matching the query.
+func (av *AirVantage) AllSystemsByQuery(ctx context.Context, q *SystemQuery) iter.Seq2[System, error] {
+	criteria, err := q.Values()
+	if err != nil {
+		return func(yield func(System, error) bool) {
+			yield(System{}, err)
+		}
+	}
+	return av.AllSystems(ctx, criteria, "", "")
+}
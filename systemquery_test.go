@@ -0,0 +1,48 @@
+package airvantage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSystemQuery(t *testing.T) {
+	from := AVTime(1000)
+	q := NewSystemQuery().
+		AnyLabels("a", "b").
+		AllLabels("c", "d").
+		LifeCycleStates(LifeCycleDeployed, LifeCycleReady).
+		CommStatuses(CommStatusError).
+		IMEI("359146140001239").
+		LastCommBetween(from, 0).
+		OrderBy("lastCommDate", true).
+		Fields("uid", "name").
+		Size(500)
+
+	v, err := q.Values()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "commStatus=ERROR&fields=uid%2Cname&gateway=imei%3A359146140001239&labels=a%2Cb&labels=c&labels=d" +
+		"&lastCommDate=1000%2C&lifeCycleState=DEPLOYED%2CREADY&orderBy=lastCommDate%3Adesc&size=500"
+	if v.Encode() != expected {
+		t.Fatalf("expected: %v, got: %v", expected, v.Encode())
+	}
+}
+
+func TestSystemQueryValidate(t *testing.T) {
+	now := NewAVTime(time.Now())
+	for name, q := range map[string]*SystemQuery{
+		"state":   NewSystemQuery().LifeCycleStates("DEAD"),
+		"status":  NewSystemQuery().CommStatuses("KO"),
+		"gateway": NewSystemQuery().IMEI("1").SerialNumber("2"),
+		"range":   NewSystemQuery().CreatedBetween(now, now-1),
+		"label":   NewSystemQuery().AnyLabels(),
+		"order":   NewSystemQuery().OrderBy("", false),
+		"size":    NewSystemQuery().Size(-1),
+	} {
+		if _, err := q.Values(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package airvantage
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strings"
+)
+
+// Task states.
+const (
+	TaskPending        = "PENDING"
+	TaskInProgress     = "IN_PROGRESS"
+	TaskSuccess        = "SUCCESS"
+	TaskFailure        = "FAILURE"
+	TaskBeingCancelled = "BEING_CANCELLED"
+	TaskCancelled      = "CANCELLED"
+)
+
+// A Task is the execution of an operation on one system.
+type Task struct {
+	UID       string `json:"uid"`
+	Operation string `json:"operation"`
+	// System is the UID of the target system.
+	System       string `json:"system"`
+	State        string `json:"state"`
+	FailureCause string `json:"failureCause,omitempty"`
+	StartDate    AVTime `json:"startDate,omitempty"`
+	EndDate      AVTime `json:"endDate,omitempty"`
+}
+
+// TaskPages returns the pages of the tasks of an operation, one per targeted
+// system. If states are given, only the tasks in these states are returned.
+func (av *AirVantage) TaskPages(ctx context.Context, opUID string, states ...string) *Pages[Task] {
+	criteria := url.Values{"operation": {opUID}}
+	if len(states) > 0 {
+		criteria.Set("state", strings.Join(states, ","))
+	}
+	return newPages[Task](ctx, av, "operations/tasks", criteria, "", "")
+}
+
+// AllTasks iterates over the tasks of an operation, see TaskPages.
+func (av *AirVantage) AllTasks(ctx context.Context, opUID string, states ...string) iter.Seq2[Task, error] {
+	return av.TaskPages(ctx, opUID, states...).All()
+}
+
+// FailedSystems returns the UIDs of the systems on which the operation failed.
+func (av *AirVantage) FailedSystems(opUID string) ([]string, error) {
+	return av.FailedSystemsContext(context.Background(), opUID)
+}
+
+// FailedSystemsContext is like FailedSystems but uses ctx for the API calls.
+func (av *AirVantage) FailedSystemsContext(ctx context.Context, opUID string) ([]string, error) {
+	var uids []string
+	for task, err := range av.AllTasks(ctx, opUID, TaskFailure) {
+		if err != nil {
+			return nil, err
+		}
+		uids = append(uids, task.System)
+	}
+	return uids, nil
+}
@@ -0,0 +1,61 @@
+package airvantage_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+func TestTasks(t *testing.T) {
+	srv, av := newServer(t)
+
+	var uids []string
+	for range 5 {
+		uids = append(uids, srv.AddSystem(airvantage.System{Name: "sys"}).UID)
+	}
+	srv.FailSystems("device.unreachable", uids[1], uids[3])
+
+	opUID, err := av.ConfigureCommunication("ON", 60, "", 0, uids, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = av.AwaitOperation(opUID, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	var tasks []airvantage.Task
+	for task, err := range av.AllTasks(context.Background(), opUID) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		tasks = append(tasks, task)
+	}
+	if len(tasks) != 5 {
+		t.Fatalf("expected 5 tasks, got: %+v", tasks)
+	}
+	for _, task := range tasks {
+		if task.Operation != opUID || task.StartDate == 0 || task.EndDate == 0 {
+			t.Fatalf("invalid task: %+v", task)
+		}
+	}
+
+	failed, err := av.FailedSystems(opUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(failed, []string{uids[1], uids[3]}) {
+		t.Fatalf("expected failed systems %v, got: %v", []string{uids[1], uids[3]}, failed)
+	}
+
+	for task, err := range av.AllTasks(context.Background(), opUID, airvantage.TaskFailure) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if task.FailureCause != "device.unreachable" {
+			t.Fatalf("expected a failure cause, got: %+v", task)
+		}
+	}
+}
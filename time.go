@@ -25,7 +25,7 @@ func (t AVTime) MarshalJSON() ([]byte, error) {
 
 // NewAVTime creates an AVTime from a Go Time struct.
 func NewAVTime(t time.Time) AVTime {
-	return AVTime(t.UnixNano() / 1000)
+	return AVTime(t.UnixMilli())
 }
 
 // Time converts an AVTime to a Go Time struct.
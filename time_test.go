@@ -39,3 +39,10 @@ func TestEncode(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestNewAVTime(t *testing.T) {
+	now := time.UnixMilli(time.Now().UnixMilli())
+	if avt := NewAVTime(now); !avt.Time().Equal(now) {
+		t.Fatalf("expected: %v, got: %v", now, avt.Time())
+	}
+}
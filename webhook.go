@@ -0,0 +1,259 @@
+package airvantage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Webhook event names.
+const (
+	EventOperationFinished = "event.operation.finished"
+	EventAlertTriggered    = "event.alert.rule.triggered"
+	EventAlertCleared      = "event.alert.rule.cleared"
+)
+
+const (
+	// maxWebhookBody is the maximum size of a callback request.
+	maxWebhookBody = 1 << 20
+	// webhookDedupSize is the number of events remembered to skip redeliveries.
+	webhookDedupSize = 1024
+)
+
+// WebhookEvent is an event sent by AirVantage to a callback URL.
+type WebhookEvent struct {
+	Name    string          `json:"name"`
+	Date    AVTime          `json:"date"`
+	Content json.RawMessage `json:"content"`
+}
+
+// OperationEvent notifies the end of an operation.
+type OperationEvent struct {
+	WebhookEvent
+	OperationUID string `json:"operation.uid"`
+	State        string `json:"operation.state"`
+}
+
+// AlertEvent notifies that an alert rule was triggered or cleared on a system.
+type AlertEvent struct {
+	WebhookEvent
+	AlertUID  string `json:"alert.uid"`
+	RuleUID   string `json:"rule.uid"`
+	SystemUID string `json:"target.uid"`
+}
+
+// Webhook is an http.Handler receiving the AirVantage callbacks, to serve at
+// the URL given as callback of the operations or alert rules. It decodes the
+// events and dispatches them to the registered handlers, once per event even
+// if it is delivered several times.
+//
+// When a secret is set, the requests must be signed with it: either an
+// X-AV-Signature header with the hex HMAC-SHA256 of the body, or the secret
+// itself in an X-AV-Secret header or a 'secret' query parameter.
+//
+// A Webhook can also be set in AwaitOptions, so that the wait of an operation
+// completes as soon as its callback is received.
+type Webhook struct {
+	secret string
+
+	mu              sync.Mutex
+	onOperation     []func(OperationEvent)
+	onAlert         []func(AlertEvent)
+	seen            map[[sha256.Size]byte]bool
+	seenOrder       [][sha256.Size]byte
+	operationWaiter map[string][]chan struct{}
+}
+
+// NewWebhook returns a webhook checking the requests with secret, if not empty.
+func NewWebhook(secret string) *Webhook {
+	return &Webhook{
+		secret:          secret,
+		seen:            map[[sha256.Size]byte]bool{},
+		operationWaiter: map[string][]chan struct{}{},
+	}
+}
+
+// OnOperation registers a handler of the operation events.
+func (wh *Webhook) OnOperation(handler func(OperationEvent)) {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+
+	wh.onOperation = append(wh.onOperation, handler)
+}
+
+// OnAlert registers a handler of the alert events.
+func (wh *Webhook) OnAlert(handler func(AlertEvent)) {
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+
+	wh.onAlert = append(wh.onAlert, handler)
+}
+
+// ServeHTTP implements http.Handler. The request body is an event or an array
+// of events. The unknown events are ignored.
+func (wh *Webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if !wh.verify(r, body) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var events []json.RawMessage
+	if body = bytes.TrimSpace(body); len(body) > 0 && body[0] == '[' {
+		err = json.Unmarshal(body, &events)
+	} else {
+		events = []json.RawMessage{body}
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, js := range events {
+		if err = wh.dispatch(js); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// verify checks the signature of a request.
+func (wh *Webhook) verify(r *http.Request, body []byte) bool {
+	if wh.secret == "" {
+		return true
+	}
+
+	if signature := r.Header.Get("X-AV-Signature"); signature != "" {
+		expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+		if err != nil {
+			return false
+		}
+		mac := hmac.New(sha256.New, []byte(wh.secret))
+		mac.Write(body)
+		return hmac.Equal(mac.Sum(nil), expected)
+	}
+
+	secret := r.Header.Get("X-AV-Secret")
+	if secret == "" {
+		secret = r.URL.Query().Get("secret")
+	}
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(wh.secret)) == 1
+}
+
+// dispatch decodes an event and calls its handlers, unless it was already received.
+func (wh *Webhook) dispatch(js json.RawMessage) error {
+	var event WebhookEvent
+	if err := json.Unmarshal(js, &event); err != nil {
+		return err
+	}
+
+	var opEvent OperationEvent
+	var alertEvent AlertEvent
+	switch event.Name {
+	case EventOperationFinished:
+		opEvent.WebhookEvent = event
+		if err := json.Unmarshal(event.Content, &opEvent); err != nil {
+			return err
+		}
+	case EventAlertTriggered, EventAlertCleared:
+		alertEvent.WebhookEvent = event
+		if err := json.Unmarshal(event.Content, &alertEvent); err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+
+	wh.mu.Lock()
+	if !wh.firstDelivery(event) {
+		wh.mu.Unlock()
+		return nil
+	}
+	onOperation, onAlert := wh.onOperation, wh.onAlert
+	if opEvent.OperationUID != "" {
+		for _, waiter := range wh.operationWaiter[opEvent.OperationUID] {
+			select {
+			case waiter <- struct{}{}:
+			default:
+			}
+		}
+	}
+	wh.mu.Unlock()
+
+	if event.Name == EventOperationFinished {
+		for _, handler := range onOperation {
+			handler(opEvent)
+		}
+	} else {
+		for _, handler := range onAlert {
+			handler(alertEvent)
+		}
+	}
+	return nil
+}
+
+// firstDelivery tells if the event is received for the first time, and
+// remembers it. wh.mu must be held.
+func (wh *Webhook) firstDelivery(event WebhookEvent) bool {
+	h := sha256.New()
+	json.NewEncoder(h).Encode(event)
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+
+	if wh.seen[key] {
+		return false
+	}
+	wh.seen[key] = true
+	wh.seenOrder = append(wh.seenOrder, key)
+	if len(wh.seenOrder) > webhookDedupSize {
+		delete(wh.seen, wh.seenOrder[0])
+		wh.seenOrder = wh.seenOrder[1:]
+	}
+	return true
+}
+
+// waitOperation returns a channel receiving a value when the end of the
+// operation is notified, and a function to call when the wait is over.
+func (wh *Webhook) waitOperation(opUID string) (<-chan struct{}, func()) {
+	waiter := make(chan struct{}, 1)
+
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	wh.operationWaiter[opUID] = append(wh.operationWaiter[opUID], waiter)
+
+	return waiter, func() {
+		wh.mu.Lock()
+		defer wh.mu.Unlock()
+
+		waiters := wh.operationWaiter[opUID]
+		for i, w := range waiters {
+			if w == waiter {
+				waiters = append(waiters[:i], waiters[i+1:]...)
+				break
+			}
+		}
+		if len(waiters) == 0 {
+			delete(wh.operationWaiter, opUID)
+		} else {
+			wh.operationWaiter[opUID] = waiters
+		}
+	}
+}
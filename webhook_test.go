@@ -0,0 +1,93 @@
+package airvantage_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	airvantage "github.com/AirVantage/airvantage-api-go"
+)
+
+const (
+	operationCallback = `{"name":"event.operation.finished","date":1700000000000,"content":{"operation.uid":"op1","operation.state":"FINISHED"}}`
+	alertCallback     = `[{"name":"event.alert.rule.triggered","date":1700000000000,"content":{"alert.uid":"a1","rule.uid":"r1","target.uid":"s1"}},` +
+		`{"name":"event.unknown","date":1700000000000,"content":{}}]`
+)
+
+func postCallback(wh http.Handler, body string, header http.Header) int {
+	req := httptest.NewRequest(http.MethodPost, "/callback", strings.NewReader(body))
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	wh.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func sign(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestWebhook(t *testing.T) {
+	wh := airvantage.NewWebhook("secret")
+
+	var ops []airvantage.OperationEvent
+	var alerts []airvantage.AlertEvent
+	wh.OnOperation(func(ev airvantage.OperationEvent) { ops = append(ops, ev) })
+	wh.OnAlert(func(ev airvantage.AlertEvent) { alerts = append(alerts, ev) })
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		expected int
+	}{
+		{"unsigned", nil, http.StatusUnauthorized},
+		{"wrong secret", http.Header{"X-Av-Secret": {"wrong"}}, http.StatusUnauthorized},
+		{"wrong signature", http.Header{"X-Av-Signature": {sign("wrong", operationCallback)}}, http.StatusUnauthorized},
+		{"signature", http.Header{"X-Av-Signature": {sign("secret", operationCallback)}}, http.StatusNoContent},
+		{"redelivery", http.Header{"X-Av-Secret": {"secret"}}, http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		if code := postCallback(wh, operationCallback, tt.header); code != tt.expected {
+			t.Errorf("%s: expected status %d, got: %d", tt.name, tt.expected, code)
+		}
+	}
+	if len(ops) != 1 || ops[0].OperationUID != "op1" || ops[0].State != airvantage.OperationFinished || ops[0].Date != 1700000000000 {
+		t.Fatalf("expected one operation event, got: %+v", ops)
+	}
+
+	if code := postCallback(wh, alertCallback, http.Header{"X-Av-Secret": {"secret"}}); code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got: %d", http.StatusNoContent, code)
+	}
+	if len(alerts) != 1 || alerts[0].Name != airvantage.EventAlertTriggered || alerts[0].AlertUID != "a1" || alerts[0].RuleUID != "r1" || alerts[0].SystemUID != "s1" {
+		t.Fatalf("expected one alert event, got: %+v", alerts)
+	}
+}
+
+func TestAwaitWebhook(t *testing.T) {
+	srv, av := newServer(t)
+
+	op := srv.AddOperation(airvantage.Operation{State: airvantage.OperationInProgress})
+	wh := airvantage.NewWebhook("")
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		srv.SetOperation(op.UID, airvantage.OperationFinished, airvantage.OperationCounters{Success: 1})
+		postCallback(wh, strings.ReplaceAll(operationCallback, "op1", op.UID), nil)
+	}()
+
+	start := time.Now()
+	res, err := av.AwaitOperationResult(op.UID, &airvantage.AwaitOptions{Interval: time.Hour, Timeout: 5 * time.Second, Webhook: wh})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.AllSucceeded || time.Since(start) > time.Second {
+		t.Fatalf("expected the wait to complete on the callback, got: %+v after %v", res, time.Since(start))
+	}
+}